@@ -13,6 +13,7 @@ import {
   evaluateTransaction, 
   submitTransaction,
   submitTransactionAsOrg,
+  submitTransactionWithTransient,
   evaluateTransactionAsOrg 
 } from './gateway';
 import { 
@@ -86,9 +87,11 @@ export async function transferCustody(
   toOrgMSP: string,
   reason: string,
   fromOrgMspId?: string
-): Promise<void> {
+): Promise<string> {
+  let result: Uint8Array;
+  
   if (fromOrgMspId) {
-    await submitTransactionAsOrg(
+    result = await submitTransactionAsOrg(
       fromOrgMspId,
       'TransferCustody',
       evidenceId,
@@ -97,7 +100,7 @@ export async function transferCustody(
       reason
     );
   } else {
-    await submitTransaction(
+    result = await submitTransaction(
       'TransferCustody',
       evidenceId,
       toEntityId,
@@ -106,7 +109,10 @@ export async function transferCustody(
     );
   }
   
-  logger.info(`Custody transferred for ${evidenceId} to ${toEntityId}`);
+  // Custody moves only when the receiver accepts the transfer
+  const transferId = Buffer.from(result).toString('utf8');
+  logger.info(`Custody transfer ${transferId} started for ${evidenceId} to ${toEntityId}`);
+  return transferId;
 }
 
 // =============================================================================
@@ -245,22 +251,13 @@ export async function submitForJudicialReview(
   caseNotes: string,
   orgMspId?: string
 ): Promise<string> {
-  let result: Uint8Array;
-  
-  if (orgMspId) {
-    result = await submitTransactionAsOrg(
-      orgMspId,
-      'SubmitForJudicialReview',
-      evidenceId,
-      caseNotes
-    );
-  } else {
-    result = await submitTransaction(
-      'SubmitForJudicialReview',
-      evidenceId,
-      caseNotes
-    );
-  }
+  // Case notes go in the transient map so they are never written to the block
+  const result = await submitTransactionWithTransient(
+    orgMspId,
+    'SubmitForJudicialReview',
+    [evidenceId, ''],
+    caseNotes ? { caseNotes } : {}
+  );
   
   const reviewId = Buffer.from(result).toString('utf8');
   logger.info(`Evidence submitted for judicial review: ${reviewId}`);
@@ -277,13 +274,11 @@ export async function recordJudicialDecision(
   // Judicial decisions should be recorded by JudiciaryMSP
   const targetOrg = orgMspId || 'JudiciaryMSP';
   
-  await submitTransactionAsOrg(
+  await submitTransactionWithTransient(
     targetOrg,
     'RecordJudicialDecision',
-    reviewId,
-    decision,
-    decisionReason,
-    courtReference
+    [reviewId, decision, '', courtReference],
+    decisionReason ? { decisionReason } : {}
   );
   
   logger.info(`Judicial decision recorded for ${reviewId}: ${decision}`);
@@ -368,7 +363,9 @@ export async function getEvidenceHistory(evidenceId: string): Promise<CustodyEve
 
 export async function getEvidenceByCase(caseId: string): Promise<Evidence[]> {
   const result = await evaluateTransaction('GetEvidenceByCase', caseId);
-  return parseResponse<Evidence[]>(result);
+  // The chaincode returns the case header alongside its evidence
+  const caseEvidence = parseResponse<{ case: unknown; evidence: Evidence[] | null }>(result);
+  return caseEvidence.evidence ?? [];
 }
 
 export async function queryByStatus(status: string): Promise<Evidence[]> {
@@ -324,6 +324,31 @@ export async function submitTransactionAsOrg(
   }
 }
 
+/**
+ * Submits a chaincode transaction with private values in the transient map
+ * Transient data is not written to the block; use it for anything the
+ * chaincode seals in a private data collection.
+ */
+export async function submitTransactionWithTransient(
+  orgMspId: string | undefined,
+  functionName: string,
+  args: string[],
+  transientData: Record<string, string>
+): Promise<Uint8Array> {
+  const contract = getContractForOrg(orgMspId);
+  
+  logger.debug(`Submitting transaction with transient data: ${functionName}`, { args, transientKeys: Object.keys(transientData) });
+  
+  try {
+    const result = await contract.submit(functionName, { arguments: args, transientData });
+    logger.info(`Transaction ${functionName} submitted successfully`);
+    return result;
+  } catch (error) {
+    logger.error(`Failed to submit transaction ${functionName}:`, error);
+    throw error;
+  }
+}
+
 /**
  * Gets the list of initialized organizations
  */
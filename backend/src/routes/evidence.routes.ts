@@ -213,7 +213,7 @@ router.get('/:id/download', requirePermission('evidence:read'), async (req: Requ
 
 /**
  * POST /api/evidence/:id/transfer
- * Transfers custody of evidence
+ * Starts a custody transfer that the receiver must accept
  */
 router.post('/:id/transfer', requirePermission('evidence:transfer'), async (req: Request, res: Response) => {
   try {
@@ -229,13 +229,14 @@ router.post('/:id/transfer', requirePermission('evidence:transfer'), async (req:
     }
     
     // Use the user's organization to sign the transaction
-    await contracts.transferCustody(id, toEntityId, toOrgMSP, reason, req.user?.mspId);
+    const transferId = await contracts.transferCustody(id, toEntityId, toOrgMSP, reason, req.user?.mspId);
     
-    logger.info(`Custody transferred: ${id} to ${toEntityId} by ${req.user?.id}`);
+    logger.info(`Custody transfer started: ${id} to ${toEntityId} by ${req.user?.id}`);
     
     res.json({
       success: true,
-      message: 'Custody transferred successfully'
+      message: 'Custody transfer started; custody moves when the receiver accepts it',
+      data: { transferId }
     });
     
   } catch (error) {
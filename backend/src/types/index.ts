@@ -13,7 +13,8 @@ export enum EvidenceStatus {
   ADMITTED = 'ADMITTED',
   REJECTED = 'REJECTED',
   ARCHIVED = 'ARCHIVED',
-  DISPOSED = 'DISPOSED'
+  DISPOSED = 'DISPOSED',
+  COMPROMISED = 'COMPROMISED'
 }
 
 // Event Types
@@ -0,0 +1,336 @@
+// Copyright Evidentia Chain-of-Custody System
+// Attribute-based access control (ABAC) on classification labels
+//
+// Design Decision: A role says what a user may do, not which evidence they may
+// do it to. Certificates can carry three more attributes: "clearance" (a
+// Classification), "unit" and "cases" (comma-separated case IDs). Evidence and
+// cases carry a classification label, and a case can be restricted to named
+// units. Evidence labelled above UNCLASSIFIED, directly or through its case, or
+// held in a unit-restricted case, is only available to callers whose clearance
+// is at least the higher of the two labels, who are assigned to the case (on
+// the ledger or through the "cases" attribute) and, if the case names units,
+// who belong to one of them. These checks come after RBAC: they can deny what a
+// role allows, never grant what it does not. Unlabelled evidence is unaffected.
+// Sensitive metadata carries its own ClassificationLevel, mirrored on the
+// evidence record as SensitiveClassification; reading it, or releasing the
+// escrowed key, also needs clearance for that label.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// classificationRank orders classification levels from lowest to highest
+var classificationRank = map[Classification]int{
+	ClassUnclassified: 0,
+	ClassRestricted:   1,
+	ClassConfidential: 2,
+	ClassSecret:       3,
+}
+
+// parseClassification resolves a classification label; empty means UNCLASSIFIED
+func parseClassification(label string) (Classification, error) {
+	level := Classification(strings.ToUpper(strings.TrimSpace(label)))
+	if level == "" {
+		return ClassUnclassified, nil
+	}
+	if _, ok := classificationRank[level]; !ok {
+		return "", fmt.Errorf("unknown classification %q: must be %s, %s, %s or %s",
+			label, ClassUnclassified, ClassRestricted, ClassConfidential, ClassSecret)
+	}
+	return level, nil
+}
+
+// labelOf returns a stored label, treating records without one as UNCLASSIFIED
+func labelOf(level Classification) Classification {
+	if level == "" {
+		return ClassUnclassified
+	}
+	return level
+}
+
+// readAttributeClaims reads the clearance, unit and cases certificate attributes
+// A missing or unrecognised clearance is treated as UNCLASSIFIED.
+func readAttributeClaims(ctx contractapi.TransactionContextInterface, identity *ClientIdentity) {
+	identity.Clearance = ClassUnclassified
+	if value, found, err := ctx.GetClientIdentity().GetAttributeValue("clearance"); err == nil && found {
+		if level, err := parseClassification(value); err == nil {
+			identity.Clearance = level
+		}
+	}
+
+	if value, found, err := ctx.GetClientIdentity().GetAttributeValue("unit"); err == nil && found {
+		identity.Unit = strings.TrimSpace(value)
+	}
+
+	if value, found, err := ctx.GetClientIdentity().GetAttributeValue("cases"); err == nil && found {
+		for _, caseID := range strings.Split(value, ",") {
+			if caseID = strings.TrimSpace(caseID); caseID != "" {
+				identity.Cases = append(identity.Cases, caseID)
+			}
+		}
+	}
+}
+
+// IsClearedFor reports whether the identity's clearance covers a classification
+func (id *ClientIdentity) IsClearedFor(level Classification) bool {
+	return classificationRank[labelOf(id.Clearance)] >= classificationRank[labelOf(level)]
+}
+
+// IsAssignedToCase reports whether the identity works on a case, on the ledger or by certificate
+// c may be nil for evidence registered before cases were tracked.
+func (id *ClientIdentity) IsAssignedToCase(caseID string, c *Case) bool {
+	for _, assigned := range id.Cases {
+		if assigned == caseID {
+			return true
+		}
+	}
+	return c != nil && c.IsAssigned(id.ID)
+}
+
+// checkAttributes applies the ABAC rules for a classification level within a case
+func checkAttributes(identity *ClientIdentity, caseID string, c *Case, level Classification) error {
+	unitRestricted := c != nil && len(c.Units) > 0
+	if labelOf(level) == ClassUnclassified && !unitRestricted {
+		return nil
+	}
+
+	if !identity.IsClearedFor(level) {
+		return fmt.Errorf("access denied: user %s with clearance %s is not cleared for %s material",
+			identity.ID, labelOf(identity.Clearance), level)
+	}
+	if !identity.IsAssignedToCase(caseID, c) {
+		return fmt.Errorf("access denied: user %s is not assigned to case %s", identity.ID, caseID)
+	}
+	if unitRestricted {
+		for _, unit := range c.Units {
+			if unit == identity.Unit {
+				return nil
+			}
+		}
+		return fmt.Errorf("access denied: case %s is restricted to units %s", caseID, strings.Join(c.Units, ", "))
+	}
+
+	return nil
+}
+
+// RequireCaseAttributes checks the caller's attributes against a case's labels
+func RequireCaseAttributes(identity *ClientIdentity, c *Case) error {
+	return checkAttributes(identity, c.CaseID, c, labelOf(c.Classification))
+}
+
+// evidenceLabel returns the classification that applies to evidence: the higher of its own and its case's
+func evidenceLabel(evidence *Evidence, c *Case) Classification {
+	level := labelOf(evidence.Classification)
+	if c != nil && classificationRank[labelOf(c.Classification)] > classificationRank[level] {
+		level = labelOf(c.Classification)
+	}
+	return level
+}
+
+// checkEvidenceAttributes checks the caller's attributes against an evidence item and its case
+// cases caches case records across calls; it may be nil.
+func checkEvidenceAttributes(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	cases map[string]*Case,
+) error {
+	c, cached := cases[evidence.CaseID]
+	if !cached {
+		var err error
+		c, err = getCase(ctx, evidence.CaseID)
+		if err != nil {
+			return err
+		}
+		if cases != nil {
+			cases[evidence.CaseID] = c
+		}
+	}
+
+	return checkAttributes(identity, evidence.CaseID, c, evidenceLabel(evidence, c))
+}
+
+// RequireEvidenceAttributes checks the caller's attributes against an evidence item and its case
+func RequireEvidenceAttributes(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, evidence *Evidence) error {
+	return checkEvidenceAttributes(ctx, identity, evidence, nil)
+}
+
+// getEvidenceForCaller reads evidence from world state after the ABAC checks
+// Like getEvidenceState it does not redact; use it in transactions acting on
+// evidence for a caller.
+func getEvidenceForCaller(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidenceID string,
+) (*Evidence, error) {
+	evidence, err := getEvidenceState(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireEvidenceAttributes(ctx, identity, evidence); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
+// requireSensitiveClearance checks the caller is cleared for the classification of an item's sensitive metadata
+func requireSensitiveClearance(identity *ClientIdentity, evidenceID string, label string) error {
+	level, err := parseClassification(label)
+	if err != nil {
+		return err
+	}
+	if !identity.IsClearedFor(level) {
+		return fmt.Errorf("access denied: user %s with clearance %s is not cleared for the %s sensitive metadata of evidence %s",
+			identity.ID, labelOf(identity.Clearance), level, evidenceID)
+	}
+	return nil
+}
+
+// resolveDescriptorClassification normalises a new item's label and checks the registrant is cleared for it
+func resolveDescriptorClassification(identity *ClientIdentity, descriptor *EvidenceDescriptor) error {
+	level, err := parseClassification(string(descriptor.Classification))
+	if err != nil {
+		return err
+	}
+	if !identity.IsClearedFor(level) {
+		return fmt.Errorf("user %s with clearance %s cannot register %s evidence", identity.ID, labelOf(identity.Clearance), level)
+	}
+	descriptor.Classification = level
+	return nil
+}
+
+// SetEvidenceClassification changes the classification label of an evidence item
+// Only the custodian organization may relabel evidence, and the caller must be
+// cleared for both the current and the new label.
+func (s *EvidenceContract) SetEvidenceClassification(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	classification string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageSensitive)
+	if err != nil {
+		return err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return err
+	}
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can classify evidence %s", evidenceID)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("a reason for the classification change is required")
+	}
+
+	level, err := parseClassification(classification)
+	if err != nil {
+		return err
+	}
+	if !identity.IsClearedFor(level) {
+		return fmt.Errorf("user %s with clearance %s cannot classify evidence as %s", identity.ID, labelOf(identity.Clearance), level)
+	}
+	previous := labelOf(evidence.Classification)
+	if level == previous {
+		return fmt.Errorf("evidence %s is already classified %s", evidenceID, level)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	evidence.Classification = level
+	evidence.UpdatedAt = timestamp
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventClassificationChanged,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"previousClassification":"%s","classification":"%s"}`, previous, level),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
+
+// SetCaseClassification changes a case's classification label and unit restriction
+// unitsJSON is a JSON array of unit names; an empty array lifts the restriction.
+// The caller must manage the case and be cleared for both the current and the
+// new label.
+func (s *EvidenceContract) SetCaseClassification(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	classification string,
+	unitsJSON string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageCases)
+	if err != nil {
+		return err
+	}
+
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := requireCaseManager(identity, c); err != nil {
+		return err
+	}
+	if err := RequireCaseAttributes(identity, c); err != nil {
+		return err
+	}
+
+	level, err := parseClassification(classification)
+	if err != nil {
+		return err
+	}
+	if !identity.IsClearedFor(level) {
+		return fmt.Errorf("user %s with clearance %s cannot classify case %s as %s", identity.ID, labelOf(identity.Clearance), caseID, level)
+	}
+
+	units := []string{}
+	if unitsJSON != "" {
+		if err := json.Unmarshal([]byte(unitsJSON), &units); err != nil {
+			return fmt.Errorf("failed to parse units: %v", err)
+		}
+	}
+	for i := range units {
+		units[i] = strings.TrimSpace(units[i])
+		if units[i] == "" {
+			return fmt.Errorf("unit names cannot be empty")
+		}
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Classification = level
+	c.Units = units
+	c.UpdatedAt = timestamp
+	if err := putCase(ctx, c); err != nil {
+		return err
+	}
+
+	emitCaseEvent(ctx, "CaseClassificationChanged", "CASE_CLASSIFICATION_CHANGED", c, identity, timestamp)
+
+	return nil
+}
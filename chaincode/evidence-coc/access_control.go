@@ -35,9 +35,20 @@ const (
 	PermGenerateReport     Permission = "GENERATE_REPORT"
 	PermExportEvidence     Permission = "EXPORT_EVIDENCE"
 	PermVerifyIntegrity    Permission = "VERIFY_INTEGRITY"
+	PermManageSensitive    Permission = "MANAGE_SENSITIVE"
+	PermViewSensitive      Permission = "VIEW_SENSITIVE"
+	PermViewSealedNotes    Permission = "VIEW_SEALED_NOTES"
+	PermManageLedger       Permission = "MANAGE_LEDGER"
+	PermManageCases        Permission = "MANAGE_CASES"
+	PermDeriveEvidence     Permission = "DERIVE_EVIDENCE"
+	PermResolveIntegrity   Permission = "RESOLVE_INTEGRITY"
+	PermApproveAction      Permission = "APPROVE_ACTION"
+	PermDisposeEvidence    Permission = "DISPOSE_EVIDENCE"
 )
 
 // RolePermissions defines which permissions each role has
+// These are the built-in defaults (policy version 0); the matrices in force are
+// read from the ledger, see governance.go.
 // Design Decision: Based on forensic workflow best practices:
 // - Collectors: Register and transfer evidence out
 // - Analysts: Receive, analyze, and transfer evidence
@@ -53,6 +64,9 @@ var RolePermissions = map[Role][]Permission{
 		PermViewEvidence,
 		PermViewAudit,
 		PermVerifyIntegrity,
+		PermManageSensitive,
+		PermViewSensitive,
+		PermDeriveEvidence,
 	},
 	RoleAnalyst: {
 		PermReceiveCustody,
@@ -65,6 +79,8 @@ var RolePermissions = map[Role][]Permission{
 		PermViewAudit,
 		PermVerifyIntegrity,
 		PermExportEvidence,
+		PermViewSensitive,
+		PermDeriveEvidence,
 	},
 	RoleSupervisor: {
 		PermRegisterEvidence,
@@ -81,6 +97,13 @@ var RolePermissions = map[Role][]Permission{
 		PermGenerateReport,
 		PermVerifyIntegrity,
 		PermExportEvidence,
+		PermManageSensitive,
+		PermViewSensitive,
+		PermManageCases,
+		PermDeriveEvidence,
+		PermResolveIntegrity,
+		PermApproveAction,
+		PermDisposeEvidence,
 	},
 	RoleLegalCounsel: {
 		PermReceiveCustody,
@@ -90,6 +113,8 @@ var RolePermissions = map[Role][]Permission{
 		PermViewAudit,
 		PermGenerateReport,
 		PermVerifyIntegrity,
+		PermViewSealedNotes,
+		PermApproveAction,
 	},
 	RoleJudge: {
 		PermRecordDecision,
@@ -97,6 +122,8 @@ var RolePermissions = map[Role][]Permission{
 		PermViewAudit,
 		PermGenerateReport,
 		PermVerifyIntegrity,
+		PermViewSealedNotes,
+		PermApproveAction,
 	},
 	RoleAuditor: {
 		PermViewEvidence,
@@ -121,50 +148,99 @@ var RolePermissions = map[Role][]Permission{
 		PermGenerateReport,
 		PermExportEvidence,
 		PermVerifyIntegrity,
+		PermManageSensitive,
+		PermViewSensitive,
+		PermViewSealedNotes,
+		PermManageLedger,
+		PermManageCases,
+		PermDeriveEvidence,
+		PermResolveIntegrity,
+		PermApproveAction,
+		PermDisposeEvidence,
 	},
 }
 
-// OrganizationPermissions defines which organizations can perform which actions
+// DefaultOrganizations is the built-in organization registry: the founding
+// member organizations with their type, default role and permissions.
+// Built-in defaults, as for RolePermissions; organizations are added or
+// changed through governance (see governance.go). Chaincode logic keys off the
+// organization type, never the MSP ID.
 // Design Decision: Aligns with paper's organizational model
-var OrganizationPermissions = map[string][]Permission{
+var DefaultOrganizations = map[string]OrganizationRecord{
 	"LawEnforcementMSP": {
-		PermRegisterEvidence,
-		PermTransferCustody,
-		PermReceiveCustody,
-		PermRequestAccess,
-		PermGrantAccess,
-		PermAddTags,
-		PermUpdateStatus,
-		PermSubmitForReview,
-		PermViewEvidence,
-		PermViewAudit,
-		PermGenerateReport,
-		PermVerifyIntegrity,
-		PermExportEvidence,
+		MSPID:       "LawEnforcementMSP",
+		Name:        "Law Enforcement",
+		Type:        OrgLawEnforcement,
+		DefaultRole: RoleSupervisor, // can register, transfer, submit for review
+		Permissions: []Permission{
+			PermRegisterEvidence,
+			PermTransferCustody,
+			PermReceiveCustody,
+			PermRequestAccess,
+			PermGrantAccess,
+			PermAddTags,
+			PermUpdateStatus,
+			PermSubmitForReview,
+			PermViewEvidence,
+			PermViewAudit,
+			PermGenerateReport,
+			PermVerifyIntegrity,
+			PermExportEvidence,
+			PermManageSensitive,
+			PermViewSensitive,
+			PermManageLedger,
+			PermManageCases,
+			PermDeriveEvidence,
+			PermResolveIntegrity,
+			PermApproveAction,
+			PermDisposeEvidence,
+		},
 	},
 	"ForensicLabMSP": {
-		PermReceiveCustody,
-		PermTransferCustody,
-		PermRequestAccess,
-		PermGrantAccess,
-		PermRecordAnalysis,
-		PermVerifyAnalysis,
-		PermAddTags,
-		PermUpdateStatus,
-		PermViewEvidence,
-		PermViewAudit,
-		PermGenerateReport,
-		PermVerifyIntegrity,
-		PermExportEvidence,
+		MSPID:       "ForensicLabMSP",
+		Name:        "Forensic Laboratory",
+		Type:        OrgLab,
+		DefaultRole: RoleAnalyst, // can record analysis
+		Permissions: []Permission{
+			PermReceiveCustody,
+			PermTransferCustody,
+			PermRequestAccess,
+			PermGrantAccess,
+			PermRecordAnalysis,
+			PermVerifyAnalysis,
+			PermAddTags,
+			PermUpdateStatus,
+			PermViewEvidence,
+			PermViewAudit,
+			PermGenerateReport,
+			PermVerifyIntegrity,
+			PermExportEvidence,
+			PermManageSensitive,
+			PermViewSensitive,
+			PermManageLedger,
+			PermDeriveEvidence,
+			PermResolveIntegrity,
+			PermApproveAction,
+			PermDisposeEvidence,
+		},
 	},
 	"JudiciaryMSP": {
-		PermReceiveCustody,
-		PermRequestAccess,
-		PermRecordDecision,
-		PermViewEvidence,
-		PermViewAudit,
-		PermGenerateReport,
-		PermVerifyIntegrity,
+		MSPID:       "JudiciaryMSP",
+		Name:        "Judiciary",
+		Type:        OrgCourt,
+		DefaultRole: RoleLegalCounsel, // can record decisions
+		Permissions: []Permission{
+			PermReceiveCustody,
+			PermRequestAccess,
+			PermRecordDecision,
+			PermViewEvidence,
+			PermViewAudit,
+			PermGenerateReport,
+			PermVerifyIntegrity,
+			PermViewSealedNotes,
+			PermManageLedger,
+			PermApproveAction,
+		},
 	},
 }
 
@@ -174,6 +250,9 @@ type ClientIdentity struct {
 	MSPID    string `json:"mspId"`
 	Role     Role   `json:"role"`
 	CommonName string `json:"commonName"`
+	Clearance Classification `json:"clearance"`       // "clearance" attribute (default UNCLASSIFIED)
+	Unit      string         `json:"unit,omitempty"`  // "unit" attribute
+	Cases     []string       `json:"cases,omitempty"` // "cases" attribute: comma-separated case IDs
 }
 
 // GetClientIdentity extracts client identity from the transaction context
@@ -198,7 +277,10 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 		role = Role(strings.ToUpper(roleAttr))
 	} else {
 		// Default role based on organization
-		role = getDefaultRoleForOrg(mspID)
+		role, err = getDefaultRoleForOrg(ctx, mspID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Get common name from certificate
@@ -208,64 +290,41 @@ func GetClientIdentity(ctx contractapi.TransactionContextInterface) (*ClientIden
 		commonName = cert.Subject.CommonName
 	}
 
-	return &ClientIdentity{
+	identity := &ClientIdentity{
 		ID:       clientID,
 		MSPID:    mspID,
 		Role:     role,
 		CommonName: commonName,
-	}, nil
-}
-
-// getDefaultRoleForOrg returns default role based on organization
-// Each organization has an appropriate default role for its typical workflow
-func getDefaultRoleForOrg(mspID string) Role {
-	switch mspID {
-	case "LawEnforcementMSP":
-		// LawEnforcement default: SUPERVISOR (can register, transfer, submit for review)
-		return RoleSupervisor
-	case "ForensicLabMSP":
-		// ForensicLab default: ANALYST (can record analysis)
-		return RoleAnalyst
-	case "JudiciaryMSP":
-		// Judiciary default: LEGAL_COUNSEL (can record decisions)
-		return RoleLegalCounsel
-	default:
-		return RoleAuditor // Least privilege default
 	}
+	readAttributeClaims(ctx, identity)
+
+	return identity, nil
 }
 
-// HasPermission checks if the client has the required permission
-func HasPermission(identity *ClientIdentity, permission Permission) bool {
-	// Check role-based permission
-	rolePerms, exists := RolePermissions[identity.Role]
-	if !exists {
-		return false
+// getDefaultRoleForOrg returns the default role of an organization in the registry
+// Each organization has an appropriate default role for its typical workflow;
+// unregistered organizations get the least privileged role.
+func getDefaultRoleForOrg(ctx contractapi.TransactionContextInterface, mspID string) (Role, error) {
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return "", err
 	}
 
-	for _, p := range rolePerms {
-		if p == permission {
-			// Also verify org-level permission
-			return hasOrgPermission(identity.MSPID, permission)
-		}
+	if org, ok := policy.Organizations[mspID]; ok && org.DefaultRole != "" {
+		return org.DefaultRole, nil
 	}
-
-	return false
+	return RoleAuditor, nil // Least privilege default
 }
 
-// hasOrgPermission checks if organization is allowed to perform action
-func hasOrgPermission(mspID string, permission Permission) bool {
-	orgPerms, exists := OrganizationPermissions[mspID]
-	if !exists {
-		return false
-	}
-
-	for _, p := range orgPerms {
-		if p == permission {
-			return true
-		}
+// HasPermission checks if the client has the required permission under the
+// permission policy in force (see governance.go)
+func HasPermission(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, permission Permission) (bool, error) {
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return false, err
 	}
 
-	return false
+	return policy.Allows(identity, permission), nil
 }
 
 // RequirePermission is a helper to check permission and return error if not allowed
@@ -275,7 +334,11 @@ func RequirePermission(ctx contractapi.TransactionContextInterface, permission P
 		return nil, err
 	}
 
-	if !HasPermission(identity, permission) {
+	allowed, err := HasPermission(ctx, identity, permission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
 		return nil, fmt.Errorf("access denied: user %s with role %s does not have permission %s",
 			identity.ID, identity.Role, permission)
 	}
@@ -283,24 +346,60 @@ func RequirePermission(ctx contractapi.TransactionContextInterface, permission P
 	return identity, nil
 }
 
-// ValidateStatusTransition checks if a status transition is allowed
-// Design Decision: Implements a state machine for evidence lifecycle
-func ValidateStatusTransition(currentStatus, newStatus EvidenceStatus) error {
-	allowedTransitions := map[EvidenceStatus][]EvidenceStatus{
-		StatusRegistered: {StatusInCustody},
-		StatusInCustody:  {StatusInAnalysis, StatusInCustody, StatusUnderReview, StatusArchived},
-		StatusInAnalysis: {StatusAnalyzed, StatusInCustody},
-		StatusAnalyzed:   {StatusUnderReview, StatusInCustody, StatusInAnalysis},
-		StatusUnderReview: {StatusAdmitted, StatusRejected, StatusInAnalysis},
-		StatusAdmitted:   {StatusArchived},
-		StatusRejected:   {StatusArchived, StatusInAnalysis},
-		StatusArchived:   {StatusDisposed},
-		StatusDisposed:   {}, // Terminal state
+// ValidateStatusTransition checks a status transition against the on-ledger lifecycle
+// Design Decision: The transition graph and the roles allowed to perform each
+// transition are read from the current LifecycleConfig (see lifecycle.go), so
+// the workflow can change without a chaincode upgrade. identity is nil for
+// transitions the chaincode makes on its own, such as quarantining evidence
+// after a failed integrity check; those are checked against the graph only.
+func ValidateStatusTransition(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	currentStatus, newStatus EvidenceStatus,
+) error {
+	lifecycle, err := getLifecycleConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	return lifecycle.Allows(identity, currentStatus, newStatus)
+}
+
+// RequireNotCompromised refuses to act on evidence quarantined by a failed integrity check
+func RequireNotCompromised(evidence *Evidence) error {
+	if evidence.Status == StatusCompromised {
+		incidentID := ""
+		if evidence.Quarantine != nil {
+			incidentID = evidence.Quarantine.IncidentID
+		}
+		return fmt.Errorf("evidence %s is quarantined after a failed integrity check (incident %s) and must be resolved by a supervisor", evidence.ID, incidentID)
+	}
+	return nil
+}
+
+// RequireNotDisposed refuses to act on disposed evidence, which is permanently read-only
+func RequireNotDisposed(evidence *Evidence) error {
+	if evidence.Status == StatusDisposed {
+		return fmt.Errorf("evidence %s has been disposed and is read-only", evidence.ID)
+	}
+	return nil
+}
+
+// ValidateAccessTransition checks if an access request status transition is allowed
+// Design Decision: PENDING -> APPROVED/DENIED -> REVOKED/EXPIRED; DENIED, REVOKED
+// and EXPIRED are terminal so a lapsed grant can never be silently reactivated.
+func ValidateAccessTransition(currentStatus, newStatus AccessRequestStatus) error {
+	allowedTransitions := map[AccessRequestStatus][]AccessRequestStatus{
+		AccessPending:  {AccessApproved, AccessDenied},
+		AccessApproved: {AccessRevoked, AccessExpired},
+		AccessDenied:   {},
+		AccessRevoked:  {},
+		AccessExpired:  {},
 	}
 
 	allowed, exists := allowedTransitions[currentStatus]
 	if !exists {
-		return fmt.Errorf("unknown current status: %s", currentStatus)
+		return fmt.Errorf("unknown access request status: %s", currentStatus)
 	}
 
 	for _, s := range allowed {
@@ -309,11 +408,40 @@ func ValidateStatusTransition(currentStatus, newStatus EvidenceStatus) error {
 		}
 	}
 
-	return fmt.Errorf("invalid status transition from %s to %s", currentStatus, newStatus)
+	return fmt.Errorf("invalid access request transition from %s to %s", currentStatus, newStatus)
+}
+
+// ValidateCaseTransition checks if a case status transition is allowed
+// Design Decision: OPEN -> ACTIVE once work starts; ACTIVE and SUSPENDED may
+// alternate; any non-closed case may be closed, and CLOSED is terminal.
+func ValidateCaseTransition(currentStatus, newStatus CaseStatus) error {
+	allowedTransitions := map[CaseStatus][]CaseStatus{
+		CaseOpen:      {CaseActive, CaseSuspended, CaseClosed},
+		CaseActive:    {CaseSuspended, CaseClosed},
+		CaseSuspended: {CaseActive, CaseClosed},
+		CaseClosed:    {},
+	}
+
+	allowed, exists := allowedTransitions[currentStatus]
+	if !exists {
+		return fmt.Errorf("unknown case status: %s", currentStatus)
+	}
+
+	for _, s := range allowed {
+		if s == newStatus {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid case transition from %s to %s", currentStatus, newStatus)
 }
 
 // ValidateCustodyTransfer checks if custody transfer is allowed
-func ValidateCustodyTransfer(identity *ClientIdentity, evidence *Evidence, toOrg string) error {
+func ValidateCustodyTransfer(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, evidence *Evidence, toOrg string) error {
+	if err := RequireNotCompromised(evidence); err != nil {
+		return err
+	}
+
 	// Must be current custodian or have transfer permission
 	if evidence.CurrentCustodian != identity.ID && evidence.CurrentOrg != identity.MSPID {
 		// Check if user is supervisor in the same org
@@ -322,14 +450,18 @@ func ValidateCustodyTransfer(identity *ClientIdentity, evidence *Evidence, toOrg
 		}
 	}
 
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Validate target organization
-	_, exists := OrganizationPermissions[toOrg]
-	if !exists {
+	if !policy.IsMember(toOrg) {
 		return fmt.Errorf("unknown target organization: %s", toOrg)
 	}
 
 	// Check if target org can receive evidence
-	if !hasOrgPermission(toOrg, PermReceiveCustody) {
+	if !policy.OrgAllows(toOrg, PermReceiveCustody) {
 		return fmt.Errorf("organization %s cannot receive custody", toOrg)
 	}
 
@@ -350,6 +482,7 @@ type AccessEntry struct {
 	GrantedBy  string       `json:"grantedBy"`
 	GrantedAt  int64        `json:"grantedAt"`
 	ExpiresAt  int64        `json:"expiresAt"`
+	RequestID  string       `json:"requestId"` // Access request that produced this entry
 }
 
 // ToJSON converts AccessControlList to JSON
@@ -357,3 +490,167 @@ func (acl *AccessControlList) ToJSON() ([]byte, error) {
 	return json.Marshal(acl)
 }
 
+// EffectiveAccess reports a caller's effective rights on a single evidence item
+type EffectiveAccess struct {
+	EvidenceID      string       `json:"evidenceId"`
+	EntityID        string       `json:"entityId"`
+	EntityOrg       string       `json:"entityOrg"`
+	Role            Role         `json:"role"`
+	IsCustodian     bool         `json:"isCustodian"`               // Caller is the current custodian
+	ActiveGrant     *AccessEntry `json:"activeGrant"`               // Unexpired grant, if any
+	CanReadContent  bool         `json:"canReadContent"`            // May read IPFS location and key reference
+	Permissions     []Permission `json:"permissions"`               // Role/org permissions
+	AttributeDenial string       `json:"attributeDenial,omitempty"` // Why the ABAC checks refuse the caller, if they do
+}
+
+// aclKey returns the world state key of an evidence item's ACL
+func aclKey(ctx contractapi.TransactionContextInterface, evidenceID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey("ACL", []string{evidenceID})
+}
+
+// GetACL reads the access control list of an evidence item (empty if none)
+func GetACL(ctx contractapi.TransactionContextInterface, evidenceID string) (*AccessControlList, error) {
+	key, err := aclKey(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	aclJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACL: %v", err)
+	}
+
+	acl := AccessControlList{EvidenceID: evidenceID, Entries: []AccessEntry{}}
+	if aclJSON != nil {
+		if err := json.Unmarshal(aclJSON, &acl); err != nil {
+			return nil, err
+		}
+	}
+
+	return &acl, nil
+}
+
+// PutACL stores the access control list of an evidence item
+func PutACL(ctx contractapi.TransactionContextInterface, acl *AccessControlList) error {
+	key, err := aclKey(ctx, acl.EvidenceID)
+	if err != nil {
+		return err
+	}
+
+	aclJSON, err := acl.ToJSON()
+	if err != nil {
+		return err
+	}
+
+	return ctx.GetStub().PutState(key, aclJSON)
+}
+
+// SetEntry adds an entry, replacing any existing entry for the same entity
+func (acl *AccessControlList) SetEntry(entry AccessEntry) {
+	entries := make([]AccessEntry, 0, len(acl.Entries)+1)
+	for _, e := range acl.Entries {
+		if e.EntityID != entry.EntityID {
+			entries = append(entries, e)
+		}
+	}
+	acl.Entries = append(entries, entry)
+}
+
+// RemoveRequest removes the entry created by an access request
+func (acl *AccessControlList) RemoveRequest(requestID string) {
+	entries := make([]AccessEntry, 0, len(acl.Entries))
+	for _, e := range acl.Entries {
+		if e.RequestID != requestID {
+			entries = append(entries, e)
+		}
+	}
+	acl.Entries = entries
+}
+
+// ActiveEntry returns the unexpired entry of an entity, or nil
+// Design Decision: Expired entries are kept for the record but never honoured.
+func (acl *AccessControlList) ActiveEntry(entityID string, now int64) *AccessEntry {
+	for i := range acl.Entries {
+		e := &acl.Entries[i]
+		if e.EntityID == entityID && e.ExpiresAt > now {
+			return e
+		}
+	}
+	return nil
+}
+
+// EvaluateAccess computes the caller's effective rights on an evidence item
+func EvaluateAccess(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, evidence *Evidence) (*EffectiveAccess, error) {
+	now, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := GetACL(ctx, evidence.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []Permission{}
+	for _, p := range policy.RolePermissions[identity.Role] {
+		if policy.OrgAllows(identity.MSPID, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	// Only the custodian reads content by right; other members of the custodian
+	// organization need a grant like everyone else
+	isCustodian := evidence.CurrentCustodian == identity.ID
+	grant := acl.ActiveEntry(identity.ID, now)
+
+	canRead := isCustodian
+	if grant != nil {
+		for _, p := range grant.Permissions {
+			if p == PermViewEvidence {
+				canRead = true
+			}
+		}
+	}
+
+	// Neither custody nor a grant overrides clearance and case assignment
+	attributeDenial := ""
+	if err := RequireEvidenceAttributes(ctx, identity, evidence); err != nil {
+		attributeDenial = err.Error()
+		canRead = false
+	}
+
+	return &EffectiveAccess{
+		EvidenceID:      evidence.ID,
+		EntityID:        identity.ID,
+		EntityOrg:       identity.MSPID,
+		Role:            identity.Role,
+		IsCustodian:     isCustodian,
+		ActiveGrant:     grant,
+		CanReadContent:  canRead,
+		Permissions:     permissions,
+		AttributeDenial: attributeDenial,
+	}, nil
+}
+
+// applyContentAccess redacts the IPFS location and encryption key reference
+// unless the caller holds custody or an unexpired grant
+func applyContentAccess(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, evidence *Evidence) error {
+	access, err := EvaluateAccess(ctx, identity, evidence)
+	if err != nil {
+		return err
+	}
+
+	if !access.CanReadContent {
+		evidence.IPFSHash = ""
+		evidence.EncryptionKeyID = ""
+		evidence.ContentRedacted = true
+	}
+
+	return nil
+}
+
@@ -0,0 +1,418 @@
+// Copyright Evidentia Chain-of-Custody System
+// Access grant lifecycle: revocation, extension, expiry and grant queries
+//
+// Design Decision: Every status change goes through ValidateAccessTransition and
+// leaves a custody event. The evidence ACL is kept in step with the request, so
+// a revoked or expired grant stops working immediately.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Access grant query filters
+const (
+	AccessFilterActive  = "ACTIVE"  // Approved and not yet expired
+	AccessFilterPending = "PENDING" // Awaiting decision
+	AccessFilterExpired = "EXPIRED" // Marked expired, or approved but past ExpiresAt
+)
+
+// getAccessRequest reads an access request from world state
+func getAccessRequest(ctx contractapi.TransactionContextInterface, requestID string) (*AccessRequest, error) {
+	requestJSON, err := ctx.GetStub().GetState(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if requestJSON == nil {
+		return nil, fmt.Errorf("access request %s not found", requestID)
+	}
+
+	var request AccessRequest
+	if err := json.Unmarshal(requestJSON, &request); err != nil {
+		return nil, err
+	}
+	if request.DocType != DocTypeAccessRequest {
+		return nil, fmt.Errorf("%s is not an access request", requestID)
+	}
+
+	return &request, nil
+}
+
+// putAccessRequest stores an access request in world state
+func putAccessRequest(ctx contractapi.TransactionContextInterface, request *AccessRequest) error {
+	requestJSON, err := request.ToJSON()
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(request.RequestID, requestJSON)
+}
+
+// indexAccessRequest writes the evidence and requester index keys of an access request
+// The keys do not depend on the status, so they are written when the request is
+// made; granting and revoking write them again so that requests made before the
+// indexes existed become visible to the grant queries.
+func indexAccessRequest(ctx contractapi.TransactionContextInterface, request *AccessRequest) error {
+	if err := putIndexEntry(ctx, idxEvidenceAccess, request.EvidenceID, request.RequestID); err != nil {
+		return err
+	}
+	return putIndexEntry(ctx, idxRequesterAccess, request.RequesterID, request.RequestID)
+}
+
+// getIndexedAccessRequests loads the access requests referenced by an index, oldest first
+func getIndexedAccessRequests(ctx contractapi.TransactionContextInterface, objectType string, key string) ([]AccessRequest, error) {
+	ids, err := getIndexedIDs(ctx, objectType, key)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []AccessRequest{}
+	for _, id := range ids {
+		request, err := getAccessRequest(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].RequestedAt < requests[j].RequestedAt
+	})
+
+	return requests, nil
+}
+
+// requireGrantingOrg checks the caller may manage grants on the request's evidence
+// Grants on disposed evidence are read-only like the evidence itself.
+func requireGrantingOrg(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, request *AccessRequest) error {
+	evidence, err := getEvidenceForCaller(ctx, identity, request.EvidenceID)
+	if err != nil {
+		return err
+	}
+	if err := RequireNotDisposed(evidence); err != nil {
+		return err
+	}
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can manage access to evidence %s", request.EvidenceID)
+	}
+	return nil
+}
+
+// RevokeAccess withdraws an approved access grant before it expires
+func (s *EvidenceContract) RevokeAccess(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermGrantAccess)
+	if err != nil {
+		return err
+	}
+
+	request, err := getAccessRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if err := ValidateAccessTransition(request.Status, AccessRevoked); err != nil {
+		return err
+	}
+	if err := requireGrantingOrg(ctx, identity, request); err != nil {
+		return err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	request.Status = AccessRevoked
+	request.RevokedBy = identity.ID
+	request.RevokedAt = timestamp
+	request.RevocationReason = reason
+	if err := putAccessRequest(ctx, request); err != nil {
+		return err
+	}
+	if err := indexAccessRequest(ctx, request); err != nil {
+		return err
+	}
+
+	acl, err := GetACL(ctx, request.EvidenceID)
+	if err != nil {
+		return err
+	}
+	acl.RemoveRequest(requestID)
+	if err := PutACL(ctx, acl); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    request.EvidenceID,
+		EventType:     EventAccessRevoked,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		ToEntity:      request.RequesterID,
+		ToOrg:         request.RequesterOrg,
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"requestId":"%s","previousExpiresAt":%d}`, requestID, request.ExpiresAt),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
+
+// ExtendAccess pushes back the expiry of an approved, unexpired access grant
+func (s *EvidenceContract) ExtendAccess(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+	additionalHours int,
+) error {
+	identity, err := RequirePermission(ctx, PermGrantAccess)
+	if err != nil {
+		return err
+	}
+
+	if additionalHours <= 0 {
+		return fmt.Errorf("additional hours must be positive")
+	}
+
+	request, err := getAccessRequest(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if request.Status != AccessApproved {
+		return fmt.Errorf("only approved access can be extended, request %s is %s", requestID, request.Status)
+	}
+	if err := requireGrantingOrg(ctx, identity, request); err != nil {
+		return err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	if request.ExpiresAt <= timestamp {
+		return fmt.Errorf("access request %s has already expired", requestID)
+	}
+
+	previousExpiry := request.ExpiresAt
+	request.ExpiresAt += int64(additionalHours) * 3600
+	request.ExtendedBy = identity.ID
+	request.ExtendedAt = timestamp
+	if err := putAccessRequest(ctx, request); err != nil {
+		return err
+	}
+
+	acl, err := GetACL(ctx, request.EvidenceID)
+	if err != nil {
+		return err
+	}
+	for i := range acl.Entries {
+		if acl.Entries[i].RequestID == requestID {
+			acl.Entries[i].ExpiresAt = request.ExpiresAt
+		}
+	}
+	if err := PutACL(ctx, acl); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    request.EvidenceID,
+		EventType:     EventAccessExtended,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		ToEntity:      request.RequesterID,
+		ToOrg:         request.RequesterOrg,
+		Reason:        fmt.Sprintf("Access extended by %d hours", additionalHours),
+		Details:       fmt.Sprintf(`{"requestId":"%s","previousExpiresAt":%d,"expiresAt":%d}`, requestID, previousExpiry, request.ExpiresAt),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
+
+// ExpireAccessGrants marks every approved grant on evidence that is past its
+// expiry as EXPIRED and removes it from the ACL
+// Returns the IDs of the requests that were expired. A single summary custody
+// event is recorded for the whole sweep.
+func (s *EvidenceContract) ExpireAccessGrants(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) ([]string, error) {
+	identity, err := RequirePermission(ctx, PermGrantAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := getEvidenceState(ctx, evidenceID); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := getIndexedAccessRequests(ctx, idxEvidenceAccess, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	acl, err := GetACL(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []string{}
+	for i := range requests {
+		request := &requests[i]
+		if request.Status != AccessApproved || request.ExpiresAt > timestamp {
+			continue
+		}
+		if err := ValidateAccessTransition(request.Status, AccessExpired); err != nil {
+			return nil, err
+		}
+
+		request.Status = AccessExpired
+		request.ExpiredAt = timestamp
+		if err := putAccessRequest(ctx, request); err != nil {
+			return nil, err
+		}
+		acl.RemoveRequest(request.RequestID)
+		expired = append(expired, request.RequestID)
+	}
+
+	if len(expired) == 0 {
+		return expired, nil
+	}
+
+	if err := PutACL(ctx, acl); err != nil {
+		return nil, err
+	}
+
+	details, err := json.Marshal(map[string]interface{}{"requestIds": expired})
+	if err != nil {
+		return nil, err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventAccessExpired,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        fmt.Sprintf("%d access grant(s) expired", len(expired)),
+		Details:       string(details),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	return expired, nil
+}
+
+// filterAccessRequests keeps the requests matching an ACTIVE, PENDING or EXPIRED filter
+func filterAccessRequests(requests []AccessRequest, filter string, now int64) ([]AccessRequest, error) {
+	result := []AccessRequest{}
+	for _, r := range requests {
+		var match bool
+		switch strings.ToUpper(filter) {
+		case AccessFilterActive:
+			match = r.Status == AccessApproved && r.ExpiresAt > now
+		case AccessFilterPending:
+			match = r.Status == AccessPending
+		case AccessFilterExpired:
+			match = r.Status == AccessExpired || (r.Status == AccessApproved && r.ExpiresAt <= now)
+		default:
+			return nil, fmt.Errorf("unknown access filter %s: must be ACTIVE, PENDING or EXPIRED", filter)
+		}
+		if match {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// GetAccessGrantsByEvidence lists the active, pending or expired access requests of an evidence item
+func (s *EvidenceContract) GetAccessGrantsByEvidence(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	filter string,
+) ([]AccessRequest, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := getIndexedAccessRequests(ctx, idxEvidenceAccess, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterAccessRequests(requests, filter, timestamp)
+}
+
+// GetAccessGrantsByRequester lists the active, pending or expired access requests of a requester
+// Requests on evidence the caller's attributes do not clear are left out.
+func (s *EvidenceContract) GetAccessGrantsByRequester(
+	ctx contractapi.TransactionContextInterface,
+	requesterID string,
+	filter string,
+) ([]AccessRequest, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := getIndexedAccessRequests(ctx, idxRequesterAccess, requesterID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []AccessRequest{}
+	cases := map[string]*Case{}
+	for _, r := range requests {
+		evidence, err := getEvidenceState(ctx, r.EvidenceID)
+		if err != nil {
+			return nil, err
+		}
+		if checkEvidenceAttributes(ctx, identity, evidence, cases) != nil {
+			continue
+		}
+		visible = append(visible, r)
+	}
+
+	return filterAccessRequests(visible, filter, timestamp)
+}
@@ -0,0 +1,745 @@
+// Copyright Evidentia Chain-of-Custody System
+// M-of-N approval of high-risk operations
+//
+// Design Decision: Disposal, cross-organization transfers and submissions to a
+// court used to happen on a single caller's say-so. When the permission policy
+// has an ApprovalRule for such an operation, the transaction that requests it
+// stores a PendingAction instead of acting, and the operation is carried out by
+// the ApproveAction transaction that brings in the last required approval.
+// Approvals must come from distinct identities other than the requester, with
+// one of the rule's roles, from an organization of one of its types. A single
+// rejection ends the request, and a request not approved within the rule's time
+// limit expires. The rule is copied into the action, so a policy change does not
+// alter requests already in flight. When the action runs, the evidence is
+// checked again with the requester's recorded identity, and the requester must
+// still hold the operation's permission and pass the ABAC checks under the
+// policy, labels and case assignments in force at that time.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// maxActionApprovals bounds ApprovalRule.Approvals
+const maxActionApprovals = 10
+
+// actionPermissions maps each operation to the permission its requesting transaction requires
+var actionPermissions = map[ActionType]Permission{
+	ActionDisposal:           PermDisposeEvidence,
+	ActionCrossOrgTransfer:   PermTransferCustody,
+	ActionJudicialSubmission: PermSubmitForReview,
+}
+
+// defaultApprovalRules returns the built-in approval rules (policy version 0)
+func defaultApprovalRules() map[ActionType]ApprovalRule {
+	return map[ActionType]ApprovalRule{
+		ActionDisposal: {
+			Approvals:  2,
+			Roles:      []Role{RoleSupervisor, RoleAdmin},
+			TTLSeconds: 7 * secondsPerDay,
+		},
+		ActionCrossOrgTransfer: {
+			Approvals:  1,
+			Roles:      []Role{RoleSupervisor, RoleAdmin},
+			TTLSeconds: 2 * secondsPerDay,
+		},
+		ActionJudicialSubmission: {
+			Approvals:  1,
+			Roles:      []Role{RoleSupervisor, RoleAdmin},
+			OrgTypes:   []OrgType{OrgLawEnforcement, OrgProsecution},
+			TTLSeconds: 7 * secondsPerDay,
+		},
+	}
+}
+
+// validate checks an approval rule only uses known roles and organization types
+func (r ApprovalRule) validate(actionType ActionType) error {
+	switch actionType {
+	case ActionDisposal, ActionCrossOrgTransfer, ActionJudicialSubmission:
+	default:
+		return fmt.Errorf("unknown action type %s", actionType)
+	}
+
+	if r.Approvals < 0 || r.Approvals > maxActionApprovals {
+		return fmt.Errorf("%s must need between 0 and %d approvals, got %d", actionType, maxActionApprovals, r.Approvals)
+	}
+	if r.Approvals > 0 && r.TTLSeconds <= 0 {
+		return fmt.Errorf("%s approvals need a positive time limit", actionType)
+	}
+	for _, role := range r.Roles {
+		if _, ok := RolePermissions[role]; !ok {
+			return fmt.Errorf("%s approval rule names unknown role %s", actionType, role)
+		}
+	}
+	for _, orgType := range r.OrgTypes {
+		if !isKnownOrgType(orgType) {
+			return fmt.Errorf("%s approval rule names unknown organization type %s", actionType, orgType)
+		}
+	}
+
+	return nil
+}
+
+// accepts checks an identity may approve under the rule
+func (r ApprovalRule) accepts(identity *ClientIdentity, policy *PermissionPolicy) error {
+	if len(r.Roles) > 0 {
+		allowed := false
+		for _, role := range r.Roles {
+			if role == identity.Role {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("role %s cannot approve this action", identity.Role)
+		}
+	}
+
+	if len(r.OrgTypes) > 0 {
+		orgType := policy.OrgType(identity.MSPID)
+		for _, t := range r.OrgTypes {
+			if t == orgType {
+				return nil
+			}
+		}
+		return fmt.Errorf("organization %s cannot approve this action", identity.MSPID)
+	}
+
+	return nil
+}
+
+// getApprovalRule returns the rule in force for an operation, or nil if it needs no approval
+func getApprovalRule(ctx contractapi.TransactionContextInterface, actionType ActionType) (*ApprovalRule, error) {
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, ok := policy.ApprovalRules[actionType]
+	if !ok || rule.Approvals == 0 {
+		return nil, nil
+	}
+	return &rule, nil
+}
+
+// getPendingAction reads a pending action from world state
+func getPendingAction(ctx contractapi.TransactionContextInterface, actionID string) (*PendingAction, error) {
+	actionJSON, err := ctx.GetStub().GetState(actionID)
+	if err != nil {
+		return nil, err
+	}
+	if actionJSON == nil {
+		return nil, fmt.Errorf("action %s not found", actionID)
+	}
+
+	var action PendingAction
+	if err := json.Unmarshal(actionJSON, &action); err != nil {
+		return nil, err
+	}
+	if action.DocType != DocTypePendingAction {
+		return nil, fmt.Errorf("%s is not a pending action", actionID)
+	}
+
+	return &action, nil
+}
+
+// putPendingAction stores a pending action
+func putPendingAction(ctx contractapi.TransactionContextInterface, action *PendingAction) error {
+	actionJSON, err := action.ToJSON()
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(action.ActionID, actionJSON)
+}
+
+// setActionStatus changes an action's status and moves its status index key
+func setActionStatus(ctx contractapi.TransactionContextInterface, action *PendingAction, status ActionStatus) error {
+	if err := deleteIndexEntry(ctx, idxActionStatus, string(action.Status), action.ActionID); err != nil {
+		return err
+	}
+	action.Status = status
+	return putIndexEntry(ctx, idxActionStatus, string(status), action.ActionID)
+}
+
+// getIndexedActions loads the pending actions referenced by an index
+func getIndexedActions(ctx contractapi.TransactionContextInterface, objectType string, attributes ...string) ([]PendingAction, error) {
+	ids, err := getIndexedIDs(ctx, objectType, attributes...)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []PendingAction{}
+	for _, id := range ids {
+		action, err := getPendingAction(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, *action)
+	}
+
+	return actions, nil
+}
+
+// emitActionEvent raises a chaincode event for a pending action
+func emitActionEvent(ctx contractapi.TransactionContextInterface, eventName string, eventType string, action *PendingAction, identity *ClientIdentity, timestamp int64) {
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       eventType,
+		"actionId":   action.ActionID,
+		"actionType": action.ActionType,
+		"evidenceId": action.EvidenceID,
+		"approvals":  len(action.Approvals),
+		"required":   action.Rule.Approvals,
+		"status":     action.Status,
+		"resolution": action.Resolution,
+		"actor":      identity.ID,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent(eventName, eventPayload)
+}
+
+// requireApproval stores a PendingAction if the policy requires approval of an operation
+// Returns nil if the operation needs no approval and the caller should carry it out.
+// The caller must have validated the operation; only one request of each type
+// may be outstanding per evidence item.
+func requireApproval(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	actionType ActionType,
+	evidence *Evidence,
+	params map[string]string,
+	reason string,
+) (*PendingAction, error) {
+	rule, err := getApprovalRule(ctx, actionType)
+	if err != nil || rule == nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getIndexedActions(ctx, idxEvidenceAction, evidence.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range existing {
+		if a.ActionType == actionType && a.Status == ActionPending && a.ExpiresAt > timestamp {
+			return nil, fmt.Errorf("%s of evidence %s is already awaiting approval under action %s", actionType, evidence.ID, a.ActionID)
+		}
+	}
+
+	action := &PendingAction{
+		DocType:            DocTypePendingAction,
+		ActionID:           GenerateID(ctx, "ACT", evidence.ID),
+		ActionType:         actionType,
+		EvidenceID:         evidence.ID,
+		Params:             params,
+		Reason:             reason,
+		Rule:               *rule,
+		RequestedBy:        identity.ID,
+		RequesterOrg:       identity.MSPID,
+		RequesterRole:      identity.Role,
+		RequesterClearance: identity.Clearance,
+		RequesterUnit:      identity.Unit,
+		RequesterCases:     identity.Cases,
+		RequestedAt:        timestamp,
+		ExpiresAt:          timestamp + rule.TTLSeconds,
+		Approvals:          []ActionApproval{},
+		Status:             ActionPending,
+		TxID:               ctx.GetStub().GetTxID(),
+	}
+
+	if err := putPendingAction(ctx, action); err != nil {
+		return nil, err
+	}
+	if err := putIndexEntry(ctx, idxActionStatus, string(action.Status), action.ActionID); err != nil {
+		return nil, err
+	}
+	if err := putIndexEntry(ctx, idxEvidenceAction, evidence.ID, action.ActionID); err != nil {
+		return nil, err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidence.ID,
+		EventType:     EventActionRequested,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		ToEntity:      params["toEntity"],
+		ToOrg:         params["toOrg"],
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"actionId":"%s","actionType":"%s","approvals":%d,"expiresAt":%d}`, action.ActionID, actionType, rule.Approvals, action.ExpiresAt),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	emitActionEvent(ctx, "ActionRequested", "ACTION_REQUESTED", action, identity, timestamp)
+
+	return action, nil
+}
+
+// executeAction carries out an approved operation as its requester
+// The requester's permission and attributes are checked again, since the
+// policy or the evidence labels may have changed while approval was pending.
+// Returns the ID of the transfer, review or disposal certificate it created, if any.
+func executeAction(ctx contractapi.TransactionContextInterface, action *PendingAction) (string, error) {
+	requester := &ClientIdentity{
+		ID:        action.RequestedBy,
+		MSPID:     action.RequesterOrg,
+		Role:      action.RequesterRole,
+		Clearance: labelOf(action.RequesterClearance),
+		Unit:      action.RequesterUnit,
+		Cases:     action.RequesterCases,
+	}
+
+	permission, ok := actionPermissions[action.ActionType]
+	if !ok {
+		return "", fmt.Errorf("unknown action type %s", action.ActionType)
+	}
+	allowed, err := HasPermission(ctx, requester, permission)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", fmt.Errorf("requester %s with role %s no longer has permission %s", requester.ID, requester.Role, permission)
+	}
+
+	evidence, err := getEvidenceState(ctx, action.EvidenceID)
+	if err != nil {
+		return "", err
+	}
+	if err := RequireEvidenceAttributes(ctx, requester, evidence); err != nil {
+		return "", fmt.Errorf("requester no longer passes the attribute checks: %v", err)
+	}
+	if err := RequireNotCompromised(evidence); err != nil {
+		return "", err
+	}
+
+	switch action.ActionType {
+	case ActionDisposal:
+		if err := validateDisposal(ctx, requester, evidence); err != nil {
+			return "", err
+		}
+		certificate, err := disposeEvidence(ctx, requester, evidence, disposalRequestFromParams(action.Params), action)
+		if err != nil {
+			return "", err
+		}
+		return certificate.CertificateID, nil
+
+	case ActionCrossOrgTransfer:
+		if evidence.InTransit {
+			return "", fmt.Errorf("evidence %s is in transit under transfer %s", evidence.ID, evidence.PendingTransferID)
+		}
+		if err := ValidateCustodyTransfer(ctx, requester, evidence, action.Params["toOrg"]); err != nil {
+			return "", err
+		}
+		return initiateTransfer(ctx, requester, evidence, action.Params["toEntity"], action.Params["toOrg"], action.Reason)
+
+	case ActionJudicialSubmission:
+		if err := ValidateStatusTransition(ctx, requester, evidence.Status, StatusUnderReview); err != nil {
+			return "", err
+		}
+		timestamp, err := GetTxTimestamp(ctx)
+		if err != nil {
+			return "", err
+		}
+		review := JudicialReview{
+			DocType:       DocTypeJudicialReview,
+			ReviewID:      action.Params["reviewId"],
+			EvidenceID:    evidence.ID,
+			CaseID:        evidence.CaseID,
+			SubmittedBy:   requester.ID,
+			SubmittedOrg:  requester.MSPID,
+			CourtOrg:      action.Params["courtOrg"],
+			SubmittedAt:   timestamp,
+			CaseNotesHash: action.Params["caseNotesHash"],
+			Decision:      "PENDING",
+		}
+		return review.ReviewID, submitJudicialReview(ctx, requester, evidence, &review)
+	}
+
+	return "", fmt.Errorf("unknown action type %s", action.ActionType)
+}
+
+// ApproveAction records the caller's approval of a pending action
+// The approval that completes the required count also carries out the
+// operation; if it can no longer be carried out the approval fails and the
+// action can only be rejected or left to expire.
+func (s *EvidenceContract) ApproveAction(
+	ctx contractapi.TransactionContextInterface,
+	actionID string,
+	comment string,
+) (*PendingAction, error) {
+	identity, err := RequirePermission(ctx, PermApproveAction)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := getPendingAction(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if action.Status != ActionPending {
+		return nil, fmt.Errorf("action %s is not pending, current status: %s", actionID, action.Status)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if action.ExpiresAt <= timestamp {
+		return nil, fmt.Errorf("action %s expired at %s", actionID, FormatTimestamp(action.ExpiresAt))
+	}
+
+	if identity.ID == action.RequestedBy {
+		return nil, fmt.Errorf("the requester cannot approve their own action")
+	}
+	for _, a := range action.Approvals {
+		if a.ApproverID == identity.ID {
+			return nil, fmt.Errorf("%s has already approved action %s", identity.ID, actionID)
+		}
+	}
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := action.Rule.accepts(identity, policy); err != nil {
+		return nil, err
+	}
+
+	// Approvers must be cleared for the evidence they sign off on
+	evidence, err := getEvidenceState(ctx, action.EvidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireEvidenceAttributes(ctx, identity, evidence); err != nil {
+		return nil, err
+	}
+
+	action.Approvals = append(action.Approvals, ActionApproval{
+		ApproverID:   identity.ID,
+		ApproverOrg:  identity.MSPID,
+		ApproverRole: identity.Role,
+		Comment:      comment,
+		ApprovedAt:   timestamp,
+	})
+
+	eventName, eventType := "ActionApproved", "ACTION_APPROVED"
+	if len(action.Approvals) >= action.Rule.Approvals {
+		result, err := executeAction(ctx, action)
+		if err != nil {
+			return nil, fmt.Errorf("action %s could not be carried out: %v", actionID, err)
+		}
+		if err := setActionStatus(ctx, action, ActionExecuted); err != nil {
+			return nil, err
+		}
+		action.Resolution = result
+		action.ResolvedBy = identity.ID
+		action.ResolvedAt = timestamp
+		eventName, eventType = "ActionExecuted", "ACTION_EXECUTED"
+	}
+
+	if err := putPendingAction(ctx, action); err != nil {
+		return nil, err
+	}
+
+	emitActionEvent(ctx, eventName, eventType, action, identity, timestamp)
+
+	return action, nil
+}
+
+// RejectAction ends a pending action without carrying it out
+// Anyone who could approve the action may reject it, and the requester may
+// withdraw it.
+func (s *EvidenceContract) RejectAction(
+	ctx contractapi.TransactionContextInterface,
+	actionID string,
+	reason string,
+) error {
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		return err
+	}
+
+	action, err := getPendingAction(ctx, actionID)
+	if err != nil {
+		return err
+	}
+	if action.Status != ActionPending {
+		return fmt.Errorf("action %s is not pending, current status: %s", actionID, action.Status)
+	}
+
+	if identity.ID != action.RequestedBy {
+		allowed, err := HasPermission(ctx, identity, PermApproveAction)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("access denied: user %s with role %s does not have permission %s",
+				identity.ID, identity.Role, PermApproveAction)
+		}
+		policy, err := getPermissionPolicy(ctx)
+		if err != nil {
+			return err
+		}
+		if err := action.Rule.accepts(identity, policy); err != nil {
+			return err
+		}
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("a reason for the rejection is required")
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := setActionStatus(ctx, action, ActionRejected); err != nil {
+		return err
+	}
+	action.Resolution = reason
+	action.ResolvedBy = identity.ID
+	action.ResolvedAt = timestamp
+	if err := putPendingAction(ctx, action); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    action.EvidenceID,
+		EventType:     EventActionRejected,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"actionId":"%s","actionType":"%s","approvals":%d}`, actionID, action.ActionType, len(action.Approvals)),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
+
+	emitActionEvent(ctx, "ActionRejected", "ACTION_REJECTED", action, identity, timestamp)
+
+	return nil
+}
+
+// ExpirePendingActions marks every pending action past its time limit EXPIRED
+// Each evidence item with an expired action gets one ACTION_EXPIRED custody
+// event. Returns the IDs of the actions expired.
+func (s *EvidenceContract) ExpirePendingActions(
+	ctx contractapi.TransactionContextInterface,
+) ([]string, error) {
+	identity, err := RequirePermission(ctx, PermApproveAction)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := getIndexedActions(ctx, idxActionStatus, string(ActionPending))
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []string{}
+	expiredByEvidence := map[string][]string{}
+	for i := range pending {
+		action := &pending[i]
+		if action.ExpiresAt > timestamp {
+			continue
+		}
+		if err := setActionStatus(ctx, action, ActionExpired); err != nil {
+			return nil, err
+		}
+		action.ResolvedBy = identity.ID
+		action.ResolvedAt = timestamp
+		if err := putPendingAction(ctx, action); err != nil {
+			return nil, err
+		}
+		expired = append(expired, action.ActionID)
+		expiredByEvidence[action.EvidenceID] = append(expiredByEvidence[action.EvidenceID], action.ActionID)
+	}
+
+	// One custody event per evidence item: the chain cannot take two in one transaction
+	evidenceIDs := make([]string, 0, len(expiredByEvidence))
+	for evidenceID := range expiredByEvidence {
+		evidenceIDs = append(evidenceIDs, evidenceID)
+	}
+	sort.Strings(evidenceIDs)
+	for _, evidenceID := range evidenceIDs {
+		actionIDs := expiredByEvidence[evidenceID]
+		details, err := json.Marshal(map[string]interface{}{"actionIds": actionIDs})
+		if err != nil {
+			return nil, err
+		}
+
+		event := CustodyEvent{
+			DocType:       DocTypeCustodyEvent,
+			EvidenceID:    evidenceID,
+			EventType:     EventActionExpired,
+			FromEntity:    identity.ID,
+			FromOrg:       identity.MSPID,
+			Reason:        fmt.Sprintf("%d pending action(s) expired", len(actionIDs)),
+			Details:       string(details),
+			Timestamp:     timestamp,
+			PerformedBy:   identity.ID,
+			PerformerOrg:  identity.MSPID,
+			PerformerRole: identity.Role,
+			TxID:          ctx.GetStub().GetTxID(),
+		}
+		if err := recordCustodyEvent(ctx, &event); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(expired) > 0 {
+		eventPayload, _ := json.Marshal(map[string]interface{}{
+			"type":      "ACTIONS_EXPIRED",
+			"actionIds": expired,
+			"expiredBy": identity.ID,
+			"timestamp": timestamp,
+		})
+		ctx.GetStub().SetEvent("ActionsExpired", eventPayload)
+	}
+
+	return expired, nil
+}
+
+// GetPendingAction returns a pending action, whatever its status
+func (s *EvidenceContract) GetPendingAction(
+	ctx contractapi.TransactionContextInterface,
+	actionID string,
+) (*PendingAction, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := getPendingAction(ctx, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, action.EvidenceID); err != nil {
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// GetOutstandingActions returns the unexpired actions awaiting approval, soonest to expire first
+// evidenceID limits the result to one evidence item; empty returns all.
+func (s *EvidenceContract) GetOutstandingActions(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) ([]PendingAction, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	return outstandingActions(ctx, identity, evidenceID)
+}
+
+// GetActionsAwaitingMyApproval returns the outstanding actions the caller may still approve
+func (s *EvidenceContract) GetActionsAwaitingMyApproval(
+	ctx contractapi.TransactionContextInterface,
+) ([]PendingAction, error) {
+	identity, err := RequirePermission(ctx, PermApproveAction)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding, err := outstandingActions(ctx, identity, "")
+	if err != nil {
+		return nil, err
+	}
+
+	awaiting := []PendingAction{}
+	for _, action := range outstanding {
+		if action.RequestedBy == identity.ID || action.Rule.accepts(identity, policy) != nil {
+			continue
+		}
+		approved := false
+		for _, a := range action.Approvals {
+			if a.ApproverID == identity.ID {
+				approved = true
+			}
+		}
+		if !approved {
+			awaiting = append(awaiting, action)
+		}
+	}
+
+	return awaiting, nil
+}
+
+// outstandingActions returns the pending, unexpired actions, optionally for one evidence item
+// Actions on evidence the caller's attributes do not clear are left out.
+func outstandingActions(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidenceID string,
+) ([]PendingAction, error) {
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := getIndexedActions(ctx, idxActionStatus, string(ActionPending))
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := []PendingAction{}
+	cases := map[string]*Case{}
+	for _, action := range pending {
+		if action.ExpiresAt <= timestamp || (evidenceID != "" && action.EvidenceID != evidenceID) {
+			continue
+		}
+		evidence, err := getEvidenceState(ctx, action.EvidenceID)
+		if err != nil {
+			return nil, err
+		}
+		if checkEvidenceAttributes(ctx, identity, evidence, cases) != nil {
+			continue
+		}
+		outstanding = append(outstanding, action)
+	}
+
+	sort.Slice(outstanding, func(i, j int) bool {
+		if outstanding[i].ExpiresAt != outstanding[j].ExpiresAt {
+			return outstanding[i].ExpiresAt < outstanding[j].ExpiresAt
+		}
+		return outstanding[i].ActionID < outstanding[j].ActionID
+	})
+
+	return outstanding, nil
+}
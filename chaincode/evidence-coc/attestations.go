@@ -0,0 +1,264 @@
+// Copyright Evidentia Chain-of-Custody System
+// Integrity attestation records
+//
+// Design Decision: IntegrityVerified and LastVerifiedAt only describe the latest
+// check, and the custody event keeps a digest prefix. Every verification now
+// also stores an IntegrityAttestation under its own key: who verified, how the
+// digests were obtained, with which tool, the full digests and the outcome.
+// An evidence -> attestation index key lets the history be read without rich
+// queries, so auditors can show that evidence was re-verified on schedule.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// secondsPerDay converts ListEvidenceDueForVerification's age to seconds
+const secondsPerDay = 24 * 60 * 60
+
+// newAttestation builds an attestation for a check made in this transaction
+func newAttestation(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidenceID string,
+	method VerificationMethod,
+	tool string,
+	digests []Digest,
+	verified bool,
+	timestamp int64,
+) *IntegrityAttestation {
+	return &IntegrityAttestation{
+		DocType:         DocTypeIntegrityAttestation,
+		AttestationID:   GenerateID(ctx, "ATT", evidenceID),
+		EvidenceID:      evidenceID,
+		VerifierID:      identity.ID,
+		VerifierOrg:     identity.MSPID,
+		VerifierRole:    identity.Role,
+		Method:          method,
+		Tool:            tool,
+		Algorithms:      DigestAlgorithms(digests),
+		ProvidedDigests: digests,
+		Verified:        verified,
+		Timestamp:       timestamp,
+		TxID:            ctx.GetStub().GetTxID(),
+	}
+}
+
+// putAttestation stores an attestation and its evidence index key
+func putAttestation(ctx contractapi.TransactionContextInterface, attestation *IntegrityAttestation) error {
+	attestationJSON, err := attestation.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(attestation.AttestationID, attestationJSON); err != nil {
+		return fmt.Errorf("failed to store attestation: %v", err)
+	}
+	return putIndexEntry(ctx, idxEvidenceAttestation, attestation.EvidenceID, attestation.AttestationID)
+}
+
+// parseVerificationMethod resolves a method a verifier may state for a whole-file check
+func parseVerificationMethod(method string) (VerificationMethod, error) {
+	switch m := VerificationMethod(strings.ToUpper(strings.TrimSpace(method))); m {
+	case VerifyFullRehash, VerifyIPFSFetch:
+		return m, nil
+	default:
+		return "", fmt.Errorf("verification method must be %s or %s, got %q", VerifyFullRehash, VerifyIPFSFetch, method)
+	}
+}
+
+// verifyEvidenceDigests checks provided digests against an evidence item and attests the result
+// A mismatch quarantines the evidence (see flagIntegrityFailure).
+func verifyEvidenceDigests(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidenceID string,
+	providedHash string,
+	method VerificationMethod,
+	tool string,
+) (*IntegrityAttestation, error) {
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// providedHash may carry several tagged digests; see MatchDigests
+	verified, digests, err := MatchDigests(evidence.RegisteredDigests(), providedHash)
+	if err != nil {
+		return nil, err
+	}
+	checkedAlgorithms := make([]string, 0, len(digests))
+	for _, d := range digests {
+		checkedAlgorithms = append(checkedAlgorithms, string(d.Algorithm))
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	attestation := newAttestation(ctx, identity, evidenceID, method, tool, digests, verified, timestamp)
+
+	evidence.IntegrityVerified = verified
+	evidence.LastVerifiedAt = timestamp
+	evidence.LastAttestationID = attestation.AttestationID
+	evidence.UpdatedAt = timestamp
+
+	// A mismatch quarantines the evidence
+	eventType := EventVerification
+	if !verified {
+		eventType = EventIntegrityFailure
+		flagIntegrityFailure(ctx, identity, evidence, strings.Join(checkedAlgorithms, ","), timestamp)
+		if evidence.Quarantine != nil {
+			attestation.IncidentID = evidence.Quarantine.IncidentID
+		}
+	}
+
+	if err := putAttestation(ctx, attestation); err != nil {
+		return nil, err
+	}
+	if err := putEvidence(ctx, evidence); err != nil {
+		return nil, err
+	}
+
+	// Record verification event; the attestation keeps the full digests
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     eventType,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        "Integrity verification",
+		Details:       fmt.Sprintf(`{"verified":%t,"algorithms":"%s","providedHash":"%s","method":"%s","attestationId":"%s","status":"%s"}`, verified, strings.Join(checkedAlgorithms, ","), TruncateString(providedHash, 19), method, attestation.AttestationID, evidence.Status),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	return attestation, nil
+}
+
+// AttestIntegrity verifies evidence integrity and returns the stored attestation
+// Parameters:
+//   - providedHash: Digest string as for VerifyIntegrity
+//   - method: FULL_REHASH or IPFS_FETCH
+//   - tool: Hashing tool and version used
+func (s *EvidenceContract) AttestIntegrity(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	providedHash string,
+	method string,
+	tool string,
+) (*IntegrityAttestation, error) {
+	identity, err := RequirePermission(ctx, PermVerifyIntegrity)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationMethod, err := parseVerificationMethod(method)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyEvidenceDigests(ctx, identity, evidenceID, providedHash, verificationMethod, tool)
+}
+
+// GetIntegrityHistory returns every integrity attestation of an evidence item, oldest first
+func (s *EvidenceContract) GetIntegrityHistory(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) ([]IntegrityAttestation, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	ids, err := getIndexedIDs(ctx, idxEvidenceAttestation, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	attestations := []IntegrityAttestation{}
+	for _, id := range ids {
+		attestationJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, err
+		}
+		if attestationJSON == nil {
+			return nil, fmt.Errorf("indexed attestation %s not found", id)
+		}
+
+		var attestation IntegrityAttestation
+		if err := json.Unmarshal(attestationJSON, &attestation); err != nil {
+			return nil, err
+		}
+		attestations = append(attestations, attestation)
+	}
+
+	sort.Slice(attestations, func(i, j int) bool {
+		if attestations[i].Timestamp != attestations[j].Timestamp {
+			return attestations[i].Timestamp < attestations[j].Timestamp
+		}
+		return attestations[i].AttestationID < attestations[j].AttestationID
+	})
+
+	return attestations, nil
+}
+
+// ListEvidenceDueForVerification returns evidence not verified within maxAgeDays, most overdue first
+// Disposed evidence is never due.
+func (s *EvidenceContract) ListEvidenceDueForVerification(
+	ctx contractapi.TransactionContextInterface,
+	maxAgeDays int,
+) ([]Evidence, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if maxAgeDays <= 0 {
+		return nil, fmt.Errorf("maximum age must be at least one day")
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := timestamp - int64(maxAgeDays)*secondsPerDay
+
+	allEvidence, err := getIndexedEvidence(ctx, identity, idxStatusEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []Evidence{}
+	for _, evidence := range allEvidence {
+		if evidence.Status == StatusDisposed || evidence.LastVerifiedAt > cutoff {
+			continue
+		}
+		due = append(due, evidence)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].LastVerifiedAt != due[j].LastVerifiedAt {
+			return due[i].LastVerifiedAt < due[j].LastVerifiedAt
+		}
+		return due[i].ID < due[j].ID
+	})
+
+	return due, nil
+}
@@ -0,0 +1,152 @@
+// Copyright Evidentia Chain-of-Custody System
+// Batch evidence registration
+//
+// Design Decision: A seizure can yield hundreds of items. Registering them one
+// transaction at a time costs an endorsement round-trip per item, and a failure
+// part way leaves the seizure half registered. RegisterEvidenceBatch validates
+// every item before writing anything: if one item is invalid nothing is written
+// and the per-item results say why. Fabric keeps only the last chaincode event
+// of a transaction, so a batch emits one summary event instead of one per item.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// maxBatchSize bounds the number of items registered in one transaction
+const maxBatchSize = 500
+
+// validateBatchItem checks one descriptor against world state and the rest of the batch
+func validateBatchItem(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	descriptor *EvidenceDescriptor,
+	seen map[string]bool,
+	caseErrors map[string]error,
+) error {
+	if descriptor.EvidenceID == "" {
+		return fmt.Errorf("evidence ID is required")
+	}
+	if _, _, err := resolveDescriptorDigests(descriptor); err != nil {
+		return err
+	}
+	if err := resolveDescriptorClassification(identity, descriptor); err != nil {
+		return err
+	}
+	if descriptor.ChunkManifest != nil {
+		if err := validateChunkManifest(descriptor.ChunkManifest, descriptor.Metadata.Size); err != nil {
+			return err
+		}
+	}
+
+	// World state does not reflect this transaction's writes, so duplicates
+	// within the batch must be caught here
+	if seen[descriptor.EvidenceID] {
+		return fmt.Errorf("evidence %s appears more than once in the batch", descriptor.EvidenceID)
+	}
+	seen[descriptor.EvidenceID] = true
+
+	evidenceJSON, err := ctx.GetStub().GetState(descriptor.EvidenceID)
+	if err != nil {
+		return fmt.Errorf("failed to read evidence: %v", err)
+	}
+	if evidenceJSON != nil {
+		return fmt.Errorf("evidence %s already exists", descriptor.EvidenceID)
+	}
+
+	caseErr, checked := caseErrors[descriptor.CaseID]
+	if !checked {
+		_, caseErr = requireOpenCase(ctx, identity, descriptor.CaseID)
+		caseErrors[descriptor.CaseID] = caseErr
+	}
+	return caseErr
+}
+
+// RegisterEvidenceBatch registers a JSON array of EvidenceDescriptor items, all or none
+// Returns per-item validation results. If any item is invalid, Registered is
+// false and no evidence is written.
+func (s *EvidenceContract) RegisterEvidenceBatch(
+	ctx contractapi.TransactionContextInterface,
+	batchJSON string,
+) (*BatchRegistrationResult, error) {
+	identity, err := RequirePermission(ctx, PermRegisterEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	var descriptors []EvidenceDescriptor
+	if err := json.Unmarshal([]byte(batchJSON), &descriptors); err != nil {
+		return nil, fmt.Errorf("failed to parse evidence batch: %v", err)
+	}
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("evidence batch is empty")
+	}
+	if len(descriptors) > maxBatchSize {
+		return nil, fmt.Errorf("evidence batch has %d items, maximum is %d", len(descriptors), maxBatchSize)
+	}
+
+	result := &BatchRegistrationResult{
+		BatchID: GenerateID(ctx, "BAT", strconv.Itoa(len(descriptors))),
+		Items:   make([]BatchItemResult, len(descriptors)),
+	}
+
+	// Validate every item before writing anything
+	seen := map[string]bool{}
+	caseErrors := map[string]error{}
+	allValid := true
+	for i := range descriptors {
+		item := BatchItemResult{
+			Index:      i,
+			EvidenceID: descriptors[i].EvidenceID,
+			Valid:      true,
+		}
+		if err := validateBatchItem(ctx, identity, &descriptors[i], seen, caseErrors); err != nil {
+			item.Valid = false
+			item.Error = err.Error()
+			allValid = false
+		}
+		result.Items[i] = item
+	}
+	if !allValid {
+		return result, nil
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evidenceIDs := make([]string, 0, len(descriptors))
+	caseIDs := []string{}
+	for i := range descriptors {
+		if _, err := storeNewEvidence(ctx, identity, &descriptors[i], nil, timestamp); err != nil {
+			return nil, err
+		}
+		evidenceIDs = append(evidenceIDs, descriptors[i].EvidenceID)
+		if !Contains(caseIDs, descriptors[i].CaseID) {
+			caseIDs = append(caseIDs, descriptors[i].CaseID)
+		}
+	}
+
+	result.Registered = true
+	result.Count = len(evidenceIDs)
+
+	// Emit a single summary event for the whole batch
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":        "EVIDENCE_BATCH_REGISTERED",
+		"batchId":     result.BatchID,
+		"evidenceIds": evidenceIDs,
+		"caseIds":     caseIDs,
+		"count":       result.Count,
+		"registrant":  identity.ID,
+		"timestamp":   timestamp,
+	})
+	ctx.GetStub().SetEvent("EvidenceBatchRegistered", eventPayload)
+
+	return result, nil
+}
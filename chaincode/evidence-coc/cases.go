@@ -0,0 +1,384 @@
+// Copyright Evidentia Chain-of-Custody System
+// Investigation cases: registration, assignments and lifecycle
+//
+// Design Decision: Evidence.CaseID used to be free text with nothing behind it.
+// Cases are now ledger records with their own state machine, and evidence can
+// only be registered against an OPEN or ACTIVE case by someone working on it.
+// Cases are stored under CASE~<caseID> so their IDs cannot collide with
+// evidence IDs, which are plain keys.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// caseKeyType is the composite key object type for case records
+const caseKeyType = "CASE"
+
+// caseKey returns the world state key of a case
+func caseKey(ctx contractapi.TransactionContextInterface, caseID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey(caseKeyType, []string{caseID})
+}
+
+// getCase reads a case from world state, returning nil if it does not exist
+func getCase(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	key, err := caseKey(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	caseJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read case: %v", err)
+	}
+	if caseJSON == nil {
+		return nil, nil
+	}
+
+	var c Case
+	if err := json.Unmarshal(caseJSON, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// getExistingCase reads a case from world state, failing if it does not exist
+func getExistingCase(ctx contractapi.TransactionContextInterface, caseID string) (*Case, error) {
+	c, err := getCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("case %s not found", caseID)
+	}
+	return c, nil
+}
+
+// putCase stores a case in world state
+func putCase(ctx contractapi.TransactionContextInterface, c *Case) error {
+	key, err := caseKey(ctx, c.CaseID)
+	if err != nil {
+		return err
+	}
+
+	caseJSON, err := c.ToJSON()
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, caseJSON)
+}
+
+// IsAssigned reports whether an identity works on the case
+func (c *Case) IsAssigned(entityID string) bool {
+	if entityID == c.OpenedBy || entityID == c.LeadInvestigator {
+		return true
+	}
+	for _, a := range c.Assignments {
+		if a.EntityID == entityID {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptsEvidence reports whether evidence may be registered against the case
+func (c *Case) AcceptsEvidence() bool {
+	return c.Status == CaseOpen || c.Status == CaseActive
+}
+
+// requireOpenCase checks the case exists, accepts new evidence and the caller works on it
+// The caller must also satisfy the case's classification and unit labels.
+func requireOpenCase(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, caseID string) (*Case, error) {
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.AcceptsEvidence() {
+		return nil, fmt.Errorf("case %s is %s and does not accept new evidence", caseID, c.Status)
+	}
+	if !c.IsAssigned(identity.ID) {
+		return nil, fmt.Errorf("user %s is not assigned to case %s", identity.ID, caseID)
+	}
+	if err := RequireCaseAttributes(identity, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// requireCaseManager checks the caller may change the case
+func requireCaseManager(identity *ClientIdentity, c *Case) error {
+	if identity.MSPID != c.OpenedOrg && identity.ID != c.LeadInvestigator {
+		return fmt.Errorf("only the lead investigator or organization %s can manage case %s", c.OpenedOrg, c.CaseID)
+	}
+	return nil
+}
+
+// emitCaseEvent emits a chaincode event for a case change
+func emitCaseEvent(ctx contractapi.TransactionContextInterface, eventName string, eventType string, c *Case, identity *ClientIdentity, timestamp int64) {
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":      eventType,
+		"caseId":    c.CaseID,
+		"status":    c.Status,
+		"actor":     identity.ID,
+		"timestamp": timestamp,
+	})
+	ctx.GetStub().SetEvent(eventName, eventPayload)
+}
+
+// RegisterCase opens a new investigation case
+func (s *EvidenceContract) RegisterCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	title string,
+	description string,
+	jurisdiction string,
+	leadInvestigator string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageCases)
+	if err != nil {
+		return err
+	}
+
+	if caseID == "" {
+		return fmt.Errorf("case ID is required")
+	}
+	existing, err := getCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("case %s already exists", caseID)
+	}
+
+	if leadInvestigator == "" {
+		leadInvestigator = identity.ID
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c := Case{
+		DocType:          DocTypeCase,
+		CaseID:           caseID,
+		Title:            title,
+		Description:      description,
+		Jurisdiction:     jurisdiction,
+		LeadInvestigator: leadInvestigator,
+		Status:           CaseOpen,
+		Assignments:      []CaseAssignment{},
+		OpenedBy:         identity.ID,
+		OpenedOrg:        identity.MSPID,
+		OpenedAt:         timestamp,
+		UpdatedAt:        timestamp,
+	}
+	if err := putCase(ctx, &c); err != nil {
+		return err
+	}
+
+	emitCaseEvent(ctx, "CaseRegistered", "CASE_REGISTERED", &c, identity, timestamp)
+
+	return nil
+}
+
+// AssignToCase assigns an identity to work on a case, or updates its role
+// The first assignment moves an OPEN case to ACTIVE.
+func (s *EvidenceContract) AssignToCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	entityID string,
+	orgMSP string,
+	role string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageCases)
+	if err != nil {
+		return err
+	}
+
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := requireCaseManager(identity, c); err != nil {
+		return err
+	}
+	if c.Status == CaseClosed {
+		return fmt.Errorf("case %s is closed", caseID)
+	}
+
+	assignedRole := Role(role)
+	if _, known := RolePermissions[assignedRole]; !known {
+		return fmt.Errorf("unknown role: %s", role)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	assignment := CaseAssignment{
+		EntityID:   entityID,
+		OrgMSP:     orgMSP,
+		Role:       assignedRole,
+		AssignedBy: identity.ID,
+		AssignedAt: timestamp,
+	}
+
+	replaced := false
+	for i := range c.Assignments {
+		if c.Assignments[i].EntityID == entityID {
+			c.Assignments[i] = assignment
+			replaced = true
+		}
+	}
+	if !replaced {
+		c.Assignments = append(c.Assignments, assignment)
+	}
+
+	if c.Status == CaseOpen {
+		c.Status = CaseActive
+		c.StatusReason = "First assignment"
+	}
+	c.UpdatedAt = timestamp
+
+	if err := putCase(ctx, c); err != nil {
+		return err
+	}
+
+	emitCaseEvent(ctx, "CaseAssigned", "CASE_ASSIGNED", c, identity, timestamp)
+
+	return nil
+}
+
+// UpdateCaseStatus suspends or resumes a case
+// Closing a case goes through CloseCase.
+func (s *EvidenceContract) UpdateCaseStatus(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	newStatus string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageCases)
+	if err != nil {
+		return err
+	}
+
+	targetStatus := CaseStatus(newStatus)
+	if targetStatus == CaseClosed {
+		return fmt.Errorf("use CloseCase to close a case")
+	}
+
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := requireCaseManager(identity, c); err != nil {
+		return err
+	}
+	if err := ValidateCaseTransition(c.Status, targetStatus); err != nil {
+		return err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Status = targetStatus
+	c.StatusReason = reason
+	c.UpdatedAt = timestamp
+	if err := putCase(ctx, c); err != nil {
+		return err
+	}
+
+	emitCaseEvent(ctx, "CaseStatusChanged", "CASE_STATUS_CHANGED", c, identity, timestamp)
+
+	return nil
+}
+
+// CloseCase closes a case; no further evidence can be registered against it
+// A case cannot be closed while any of its evidence is in transit.
+func (s *EvidenceContract) CloseCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageCases)
+	if err != nil {
+		return err
+	}
+
+	if reason == "" {
+		return fmt.Errorf("a reason is required to close a case")
+	}
+
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if err := requireCaseManager(identity, c); err != nil {
+		return err
+	}
+	if err := ValidateCaseTransition(c.Status, CaseClosed); err != nil {
+		return err
+	}
+
+	evidenceIDs, err := getIndexedIDs(ctx, idxCaseEvidence, caseID)
+	if err != nil {
+		return err
+	}
+	for _, evidenceID := range evidenceIDs {
+		evidence, err := getEvidenceState(ctx, evidenceID)
+		if err != nil {
+			return err
+		}
+		if evidence.InTransit {
+			return fmt.Errorf("evidence %s is in transit under transfer %s", evidenceID, evidence.PendingTransferID)
+		}
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.Status = CaseClosed
+	c.StatusReason = reason
+	c.ClosedBy = identity.ID
+	c.ClosedAt = timestamp
+	c.UpdatedAt = timestamp
+	if err := putCase(ctx, c); err != nil {
+		return err
+	}
+
+	emitCaseEvent(ctx, "CaseClosed", "CASE_CLOSED", c, identity, timestamp)
+
+	return nil
+}
+
+// GetCase retrieves a case record
+func (s *EvidenceContract) GetCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+) (*Case, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := getExistingCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := RequireCaseAttributes(identity, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -12,8 +12,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"sort"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
@@ -36,9 +34,10 @@ func (s *EvidenceContract) InitLedger(ctx contractapi.TransactionContextInterfac
 // RegisterEvidence registers a new piece of digital evidence
 // Parameters:
 //   - evidenceID: Unique identifier for the evidence
-//   - caseID: Associated case number
+//   - caseID: Registered case (must be OPEN or ACTIVE)
 //   - ipfsHash: IPFS CID where encrypted evidence is stored
-//   - evidenceHash: SHA-256 hash of the original evidence file
+//   - evidenceHash: Digests of the original evidence file as "<algorithm>:<hex>,...",
+//     primary first; a bare hex value is read as SHA-256 (see digests.go)
 //   - encryptionKeyID: Reference to the encryption key
 //   - metadataJSON: JSON string containing EvidenceMetadata
 func (s *EvidenceContract) RegisterEvidence(
@@ -56,6 +55,11 @@ func (s *EvidenceContract) RegisterEvidence(
 		return err
 	}
 
+	// Evidence can only be added to an open case the caller works on
+	if _, err := requireOpenCase(ctx, identity, caseID); err != nil {
+		return err
+	}
+
 	// Check if evidence already exists
 	exists, err := s.EvidenceExists(ctx, evidenceID)
 	if err != nil {
@@ -71,62 +75,22 @@ func (s *EvidenceContract) RegisterEvidence(
 		return fmt.Errorf("failed to parse metadata: %v", err)
 	}
 
-	// Create evidence record
-	timestamp := time.Now().Unix()
-	evidence := Evidence{
-		DocType:           DocTypeEvidence,
-		ID:                evidenceID,
-		CaseID:            caseID,
-		IPFSHash:          ipfsHash,
-		EvidenceHash:      evidenceHash,
-		EncryptionKeyID:   encryptionKeyID,
-		Metadata:          metadata,
-		Status:            StatusRegistered,
-		CurrentCustodian:  identity.ID,
-		CurrentOrg:        identity.MSPID,
-		RegisteredBy:      identity.ID,
-		CreatedAt:         timestamp,
-		UpdatedAt:         timestamp,
-		Tags:              []string{},
-		IntegrityVerified: true,
-		LastVerifiedAt:    timestamp,
-	}
-
-	// Store evidence
-	evidenceJSON, err := evidence.ToJSON()
+	timestamp, err := GetTxTimestamp(ctx)
 	if err != nil {
 		return err
 	}
-	if err := ctx.GetStub().PutState(evidenceID, evidenceJSON); err != nil {
-		return fmt.Errorf("failed to store evidence: %v", err)
-	}
 
-	// Record registration event
-	event := CustodyEvent{
-		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
-		EvidenceID:    evidenceID,
-		EventType:     EventRegistration,
-		ToEntity:      identity.ID,
-		ToOrg:         identity.MSPID,
-		Reason:        "Initial evidence registration",
-		Details:       fmt.Sprintf(`{"caseId":"%s","ipfsHash":"%s"}`, caseID, ipfsHash),
-		Timestamp:     timestamp,
-		PerformedBy:   identity.ID,
-		PerformerOrg:  identity.MSPID,
-		PerformerRole: identity.Role,
-		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
+	descriptor := EvidenceDescriptor{
+		EvidenceID:      evidenceID,
+		CaseID:          caseID,
+		IPFSHash:        ipfsHash,
+		EvidenceHash:    evidenceHash,
+		EncryptionKeyID: encryptionKeyID,
+		Metadata:        metadata,
 	}
-
-	eventJSON, err := event.ToJSON()
-	if err != nil {
+	if _, err := storeNewEvidence(ctx, identity, &descriptor, nil, timestamp); err != nil {
 		return err
 	}
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	if err := ctx.GetStub().PutState(eventKey, eventJSON); err != nil {
-		return fmt.Errorf("failed to store custody event: %v", err)
-	}
 
 	// Emit event for external systems
 	eventPayload, _ := json.Marshal(map[string]interface{}{
@@ -141,103 +105,129 @@ func (s *EvidenceContract) RegisterEvidence(
 	return nil
 }
 
-// =============================================================================
-// Custody Transfer
-// =============================================================================
+// evidenceLineage links new evidence to the items it was derived from
+type evidenceLineage struct {
+	ParentIDs  []string
+	Derivation *Derivation
+}
 
-// TransferCustody transfers custody of evidence to another entity
-func (s *EvidenceContract) TransferCustody(
+// storeNewEvidence creates an evidence record in the caller's custody and records its registration event
+// Callers must already have checked the case and that the evidence ID is unused.
+// lineage is nil unless the item is derived; RegisterDerivedEvidence checks the parents.
+func storeNewEvidence(
 	ctx contractapi.TransactionContextInterface,
-	evidenceID string,
-	toEntityID string,
-	toOrgMSP string,
-	reason string,
-) error {
-	// Verify permission
-	identity, err := RequirePermission(ctx, PermTransferCustody)
+	identity *ClientIdentity,
+	descriptor *EvidenceDescriptor,
+	lineage *evidenceLineage,
+	timestamp int64,
+) (*Evidence, error) {
+	digests, primary, err := resolveDescriptorDigests(descriptor)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// Get evidence
-	evidence, err := s.GetEvidence(ctx, evidenceID)
-	if err != nil {
-		return err
+	if err := resolveDescriptorClassification(identity, descriptor); err != nil {
+		return nil, err
 	}
-
-	// Validate transfer
-	if err := ValidateCustodyTransfer(identity, evidence, toOrgMSP); err != nil {
-		return err
+	if descriptor.ChunkManifest != nil {
+		if err := validateChunkManifest(descriptor.ChunkManifest, descriptor.Metadata.Size); err != nil {
+			return nil, err
+		}
 	}
 
-	// Record current custodian for event
-	fromEntity := evidence.CurrentCustodian
-	fromOrg := evidence.CurrentOrg
-
-	// Update evidence
-	timestamp := time.Now().Unix()
-	evidence.CurrentCustodian = toEntityID
-	evidence.CurrentOrg = toOrgMSP
-	evidence.UpdatedAt = timestamp
-	
-	// Update status if transitioning to analysis
-	if toOrgMSP == "ForensicLabMSP" && evidence.Status == StatusInCustody {
-		evidence.Status = StatusInAnalysis
-	} else if evidence.Status == StatusRegistered {
-		evidence.Status = StatusInCustody
+	evidence := Evidence{
+		DocType:           DocTypeEvidence,
+		ID:                descriptor.EvidenceID,
+		CaseID:            descriptor.CaseID,
+		IPFSHash:          descriptor.IPFSHash,
+		EncryptionKeyID:   descriptor.EncryptionKeyID,
+		Metadata:          descriptor.Metadata,
+		Status:            StatusRegistered,
+		CurrentCustodian:  identity.ID,
+		CurrentOrg:        identity.MSPID,
+		RegisteredBy:      identity.ID,
+		CreatedAt:         timestamp,
+		UpdatedAt:         timestamp,
+		Tags:              []string{},
+		IntegrityVerified: true,
+		LastVerifiedAt:    timestamp,
+		ChunkManifest:     descriptor.ChunkManifest,
+		Classification:    descriptor.Classification,
+	}
+	evidence.SetDigests(digests, primary)
+	if lineage != nil {
+		evidence.ParentEvidenceIDs = lineage.ParentIDs
+		evidence.Derivation = lineage.Derivation
 	}
 
-	// Store updated evidence
-	evidenceJSON, err := evidence.ToJSON()
-	if err != nil {
-		return err
+	// Store evidence
+	if err := putEvidence(ctx, &evidence); err != nil {
+		return nil, err
 	}
-	if err := ctx.GetStub().PutState(evidenceID, evidenceJSON); err != nil {
-		return err
+
+	reason := "Initial evidence registration"
+	details := fmt.Sprintf(`{"caseId":"%s","ipfsHash":"%s","primaryHashAlgorithm":"%s"}`, descriptor.CaseID, descriptor.IPFSHash, primary)
+	if lineage != nil {
+		for _, parentID := range lineage.ParentIDs {
+			if err := putIndexEntry(ctx, idxEvidenceDerived, parentID, evidence.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		detailsJSON, err := json.Marshal(map[string]interface{}{
+			"caseId":               descriptor.CaseID,
+			"ipfsHash":             descriptor.IPFSHash,
+			"primaryHashAlgorithm": primary,
+			"parentEvidenceIds":    lineage.ParentIDs,
+			"method":               lineage.Derivation.Method,
+			"tool":                 lineage.Derivation.Tool,
+			"toolVersion":          lineage.Derivation.ToolVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		reason = fmt.Sprintf("Derived by %s", lineage.Derivation.Method)
+		details = string(detailsJSON)
 	}
 
-	// Record transfer event
+	// Record registration event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
-		EvidenceID:    evidenceID,
-		EventType:     EventTransfer,
-		FromEntity:    fromEntity,
-		FromOrg:       fromOrg,
-		ToEntity:      toEntityID,
-		ToOrg:         toOrgMSP,
+		EvidenceID:    descriptor.EvidenceID,
+		EventType:     EventRegistration,
+		ToEntity:      identity.ID,
+		ToOrg:         identity.MSPID,
 		Reason:        reason,
-		Details:       fmt.Sprintf(`{"previousStatus":"%s","newStatus":"%s"}`, evidence.Status, evidence.Status),
+		Details:       details,
 		Timestamp:     timestamp,
 		PerformedBy:   identity.ID,
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
-	}
-
-	eventJSON, err := event.ToJSON()
-	if err != nil {
-		return err
 	}
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	if err := ctx.GetStub().PutState(eventKey, eventJSON); err != nil {
-		return err
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
 	}
 
-	// Emit event
-	eventPayload, _ := json.Marshal(map[string]interface{}{
-		"type":       "CUSTODY_TRANSFERRED",
-		"evidenceId": evidenceID,
-		"from":       fromEntity,
-		"fromOrg":    fromOrg,
-		"to":         toEntityID,
-		"toOrg":      toOrgMSP,
-		"timestamp":  timestamp,
-	})
-	ctx.GetStub().SetEvent("CustodyTransferred", eventPayload)
+	return &evidence, nil
+}
 
-	return nil
+// =============================================================================
+// Custody Transfer
+// =============================================================================
+
+// TransferCustody starts a custody handover to another entity
+// Deprecated: use InitiateTransfer. Custody no longer moves in a single step:
+// like InitiateTransfer this puts the evidence in transit until the receiver
+// calls AcceptTransfer, and returns the transfer ID (or the PendingAction ID
+// when the handover needs approval). Kept for existing clients.
+func (s *EvidenceContract) TransferCustody(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	toEntityID string,
+	toOrgMSP string,
+	reason string,
+) (string, error) {
+	return s.InitiateTransfer(ctx, evidenceID, toEntityID, toOrgMSP, reason)
 }
 
 // =============================================================================
@@ -256,15 +246,21 @@ func (s *EvidenceContract) RequestAccess(
 		return "", err
 	}
 
-	// Verify evidence exists
-	_, err = s.GetEvidence(ctx, evidenceID)
+	// Verify evidence exists and still has content to access
+	evidence, err := getEvidenceState(ctx, evidenceID)
 	if err != nil {
 		return "", err
 	}
+	if err := RequireNotDisposed(evidence); err != nil {
+		return "", err
+	}
 
 	// Create access request
-	timestamp := time.Now().Unix()
-	requestID := fmt.Sprintf("REQ-%s-%s-%d", evidenceID, identity.ID[:8], timestamp)
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+	requestID := GenerateID(ctx, "REQ", evidenceID, identity.ID)
 
 	request := AccessRequest{
 		DocType:       DocTypeAccessRequest,
@@ -275,7 +271,7 @@ func (s *EvidenceContract) RequestAccess(
 		RequesterRole: identity.Role,
 		Purpose:       purpose,
 		RequestedAt:   timestamp,
-		Status:        "PENDING",
+		Status:        AccessPending,
 	}
 
 	requestJSON, err := request.ToJSON()
@@ -285,11 +281,13 @@ func (s *EvidenceContract) RequestAccess(
 	if err := ctx.GetStub().PutState(requestID, requestJSON); err != nil {
 		return "", err
 	}
+	if err := indexAccessRequest(ctx, &request); err != nil {
+		return "", err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
 		EvidenceID:    evidenceID,
 		EventType:     EventAccessRequest,
 		FromEntity:    identity.ID,
@@ -301,15 +299,11 @@ func (s *EvidenceContract) RequestAccess(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, err := event.ToJSON()
-	if err != nil {
+	if err := recordCustodyEvent(ctx, &event); err != nil {
 		return "", err
 	}
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
 
 	return requestID, nil
 }
@@ -340,15 +334,21 @@ func (s *EvidenceContract) GrantAccess(
 		return err
 	}
 
-	if request.Status != "PENDING" {
-		return fmt.Errorf("access request is not pending")
+	if err := ValidateAccessTransition(request.Status, AccessApproved); err != nil {
+		return err
+	}
+	if expirationHours <= 0 {
+		return fmt.Errorf("expiration hours must be positive")
 	}
 
 	// Get evidence to verify current org
-	evidence, err := s.GetEvidence(ctx, request.EvidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, request.EvidenceID)
 	if err != nil {
 		return err
 	}
+	if err := RequireNotDisposed(evidence); err != nil {
+		return err
+	}
 
 	// Only current custodian's org can grant access
 	if identity.MSPID != evidence.CurrentOrg {
@@ -356,8 +356,11 @@ func (s *EvidenceContract) GrantAccess(
 	}
 
 	// Update request
-	timestamp := time.Now().Unix()
-	request.Status = "APPROVED"
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	request.Status = AccessApproved
 	request.ApprovedBy = identity.ID
 	request.ApprovedAt = timestamp
 	request.ExpiresAt = timestamp + int64(expirationHours*3600)
@@ -369,11 +372,31 @@ func (s *EvidenceContract) GrantAccess(
 	if err := ctx.GetStub().PutState(requestID, requestJSON); err != nil {
 		return err
 	}
+	if err := indexAccessRequest(ctx, &request); err != nil {
+		return err
+	}
+
+	// Add the grant to the evidence ACL
+	acl, err := GetACL(ctx, request.EvidenceID)
+	if err != nil {
+		return err
+	}
+	acl.SetEntry(AccessEntry{
+		EntityID:    request.RequesterID,
+		EntityOrg:   request.RequesterOrg,
+		Permissions: []Permission{PermViewEvidence},
+		GrantedBy:   identity.ID,
+		GrantedAt:   timestamp,
+		ExpiresAt:   request.ExpiresAt,
+		RequestID:   requestID,
+	})
+	if err := PutACL(ctx, acl); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", request.EvidenceID, timestamp),
 		EvidenceID:    request.EvidenceID,
 		EventType:     EventAccessGranted,
 		FromEntity:    identity.ID,
@@ -387,15 +410,11 @@ func (s *EvidenceContract) GrantAccess(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, err := event.ToJSON()
-	if err != nil {
+	if err := recordCustodyEvent(ctx, &event); err != nil {
 		return err
 	}
-	eventKey := fmt.Sprintf("EVENT~%s~%d", request.EvidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
 
 	return nil
 }
@@ -424,20 +443,37 @@ func (s *EvidenceContract) DenyAccess(
 		return err
 	}
 
-	timestamp := time.Now().Unix()
-	request.Status = "DENIED"
+	// Only pending requests can be denied
+	if err := ValidateAccessTransition(request.Status, AccessDenied); err != nil {
+		return err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, request.EvidenceID)
+	if err != nil {
+		return err
+	}
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can deny access")
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	request.Status = AccessDenied
 	request.DenialReason = reason
 
 	requestJSON, err = request.ToJSON()
 	if err != nil {
 		return err
 	}
-	ctx.GetStub().PutState(requestID, requestJSON)
+	if err := ctx.GetStub().PutState(requestID, requestJSON); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", request.EvidenceID, timestamp),
 		EvidenceID:    request.EvidenceID,
 		EventType:     EventAccessDenied,
 		FromEntity:    identity.ID,
@@ -450,16 +486,33 @@ func (s *EvidenceContract) DenyAccess(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", request.EvidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
 
 	return nil
 }
 
+// CheckAccess reports the caller's effective rights on an evidence item
+func (s *EvidenceContract) CheckAccess(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*EffectiveAccess, error) {
+	identity, err := GetClientIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceState(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateAccess(ctx, identity, evidence)
+}
+
 // =============================================================================
 // Analysis Operations
 // =============================================================================
@@ -482,11 +535,15 @@ func (s *EvidenceContract) RecordAnalysis(
 	}
 
 	// Get evidence
-	evidence, err := s.GetEvidence(ctx, evidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
 	if err != nil {
 		return "", err
 	}
 
+	if err := RequireNotCompromised(evidence); err != nil {
+		return "", err
+	}
+
 	// For demo: Only verify the evidence is in a valid state for analysis
 	// In production, this would check that the caller's org matches CurrentOrg
 	// But since the backend uses a single gateway connection, we relax this check
@@ -501,8 +558,11 @@ func (s *EvidenceContract) RecordAnalysis(
 	}
 
 	// Create analysis record
-	timestamp := time.Now().Unix()
-	analysisID := fmt.Sprintf("ANL-%s-%d", evidenceID, timestamp)
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+	analysisID := GenerateID(ctx, "ANL", evidenceID)
 
 	analysis := AnalysisRecord{
 		DocType:        DocTypeAnalysisRecord,
@@ -528,19 +588,25 @@ func (s *EvidenceContract) RecordAnalysis(
 	if err := ctx.GetStub().PutState(analysisID, analysisJSON); err != nil {
 		return "", err
 	}
+	if err := putIndexEntry(ctx, idxEvidenceAnalysis, evidenceID, analysisID); err != nil {
+		return "", err
+	}
 
 	// Update evidence status if needed
 	if evidence.Status == StatusInAnalysis {
+		if err := ValidateStatusTransition(ctx, identity, evidence.Status, StatusAnalyzed); err != nil {
+			return "", err
+		}
 		evidence.Status = StatusAnalyzed
 		evidence.UpdatedAt = timestamp
-		evidenceJSON, _ := evidence.ToJSON()
-		ctx.GetStub().PutState(evidenceID, evidenceJSON)
+		if err := putEvidence(ctx, evidence); err != nil {
+			return "", err
+		}
 	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
 		EvidenceID:    evidenceID,
 		EventType:     EventAnalysisEnd,
 		FromEntity:    identity.ID,
@@ -552,12 +618,11 @@ func (s *EvidenceContract) RecordAnalysis(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return "", err
+	}
 
 	// Emit event
 	eventPayload, _ := json.Marshal(map[string]interface{}{
@@ -595,7 +660,10 @@ func (s *EvidenceContract) VerifyAnalysis(
 		return err
 	}
 
-	timestamp := time.Now().Unix()
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	analysis.Verified = true
 	analysis.VerifiedBy = identity.ID
 	analysis.VerifiedAt = timestamp
@@ -611,6 +679,11 @@ func (s *EvidenceContract) VerifyAnalysis(
 // =============================================================================
 
 // SubmitForJudicialReview submits evidence for judicial review
+// Case notes are passed in the transient map ("caseNotes"). The caseNotes
+// argument is deprecated and must be empty: arguments are written to the block.
+// When the policy requires approval of judicial submissions the notes are
+// sealed now and the ID of the PendingAction is returned instead of a review
+// ID; the review is created when the last approval is given.
 func (s *EvidenceContract) SubmitForJudicialReview(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
@@ -620,71 +693,129 @@ func (s *EvidenceContract) SubmitForJudicialReview(
 	if err != nil {
 		return "", err
 	}
+	if err := rejectPlaintextNote("caseNotes", caseNotes, transientCaseNotes); err != nil {
+		return "", err
+	}
 
-	evidence, err := s.GetEvidence(ctx, evidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
 	if err != nil {
 		return "", err
 	}
 
 	// Validate status transition
-	if err := ValidateStatusTransition(evidence.Status, StatusUnderReview); err != nil {
+	if err := RequireNotCompromised(evidence); err != nil {
+		return "", err
+	}
+	if err := ValidateStatusTransition(ctx, identity, evidence.Status, StatusUnderReview); err != nil {
 		return "", err
 	}
 
-	timestamp := time.Now().Unix()
-	reviewID := fmt.Sprintf("REV-%s-%d", evidenceID, timestamp)
+	// Route the review to the court registered for the case's jurisdiction
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return "", err
+	}
+	jurisdiction := ""
+	if c, err := getCase(ctx, evidence.CaseID); err != nil {
+		return "", err
+	} else if c != nil {
+		jurisdiction = c.Jurisdiction
+	}
+	courtOrg, err := policy.CourtFor(jurisdiction)
+	if err != nil {
+		return "", err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
 
 	review := JudicialReview{
 		DocType:      DocTypeJudicialReview,
-		ReviewID:     reviewID,
+		ReviewID:     GenerateID(ctx, "REV", evidenceID),
 		EvidenceID:   evidenceID,
 		CaseID:       evidence.CaseID,
 		SubmittedBy:  identity.ID,
 		SubmittedOrg: identity.MSPID,
+		CourtOrg:     courtOrg,
 		SubmittedAt:  timestamp,
-		CaseNotes:    caseNotes,
 		Decision:     "PENDING",
 	}
 
-	reviewJSON, err := review.ToJSON()
+	// Seal case notes in the judicialNotes collection; only the hash stays public
+	review.CaseNotesHash, err = sealJudicialNote(ctx, &review, NoteKindCaseNotes, transientCaseNotes, identity, timestamp)
 	if err != nil {
 		return "", err
 	}
-	ctx.GetStub().PutState(reviewID, reviewJSON)
+
+	action, err := requireApproval(ctx, identity, ActionJudicialSubmission, evidence, map[string]string{
+		"reviewId":      review.ReviewID,
+		"courtOrg":      courtOrg,
+		"caseNotesHash": review.CaseNotesHash,
+	}, "Submitted for judicial review")
+	if err != nil {
+		return "", err
+	}
+	if action != nil {
+		return action.ActionID, nil
+	}
+
+	if err := submitJudicialReview(ctx, identity, evidence, &review); err != nil {
+		return "", err
+	}
+
+	return review.ReviewID, nil
+}
+
+// submitJudicialReview stores a prepared review and moves the evidence UNDER_REVIEW
+func submitJudicialReview(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	review *JudicialReview,
+) error {
+	reviewJSON, err := review.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(review.ReviewID, reviewJSON); err != nil {
+		return err
+	}
+	if err := putIndexEntry(ctx, idxEvidenceReview, evidence.ID, review.ReviewID); err != nil {
+		return err
+	}
 
 	// Update evidence status
 	evidence.Status = StatusUnderReview
-	evidence.UpdatedAt = timestamp
-	evidenceJSON, _ := evidence.ToJSON()
-	ctx.GetStub().PutState(evidenceID, evidenceJSON)
+	evidence.UpdatedAt = review.SubmittedAt
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
-		EvidenceID:    evidenceID,
+		EvidenceID:    evidence.ID,
 		EventType:     EventJudicialSubmit,
 		FromEntity:    identity.ID,
 		FromOrg:       identity.MSPID,
-		ToOrg:         "JudiciaryMSP",
+		ToOrg:         review.CourtOrg,
 		Reason:        "Submitted for judicial review",
-		Details:       fmt.Sprintf(`{"reviewId":"%s","caseId":"%s"}`, reviewID, evidence.CaseID),
-		Timestamp:     timestamp,
+		Details:       fmt.Sprintf(`{"reviewId":"%s","caseId":"%s"}`, review.ReviewID, evidence.CaseID),
+		Timestamp:     review.SubmittedAt,
 		PerformedBy:   identity.ID,
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
-
-	return reviewID, nil
+	return recordCustodyEvent(ctx, &event)
 }
 
 // RecordJudicialDecision records a judicial decision on evidence
+// The decision reason is passed in the transient map ("decisionReason"). The
+// decisionReason argument is deprecated and must be empty.
 func (s *EvidenceContract) RecordJudicialDecision(
 	ctx contractapi.TransactionContextInterface,
 	reviewID string,
@@ -696,6 +827,9 @@ func (s *EvidenceContract) RecordJudicialDecision(
 	if err != nil {
 		return err
 	}
+	if err := rejectPlaintextNote("decisionReason", decisionReason, transientDecisionReason); err != nil {
+		return err
+	}
 
 	reviewJSON, err := ctx.GetStub().GetState(reviewID)
 	if err != nil {
@@ -713,41 +847,61 @@ func (s *EvidenceContract) RecordJudicialDecision(
 	if review.Decision != "PENDING" {
 		return fmt.Errorf("decision already recorded for this review")
 	}
+	if review.CourtOrg != "" && identity.MSPID != review.CourtOrg {
+		return fmt.Errorf("only %s can decide review %s", review.CourtOrg, reviewID)
+	}
 
 	// Validate decision
 	if decision != "ADMITTED" && decision != "REJECTED" {
 		return fmt.Errorf("invalid decision: must be ADMITTED or REJECTED")
 	}
 
-	timestamp := time.Now().Unix()
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	review.Decision = decision
-	review.DecisionReason = decisionReason
+	review.DecisionReasonHash, err = sealJudicialNote(ctx, &review, NoteKindDecisionReason, transientDecisionReason, identity, timestamp)
+	if err != nil {
+		return err
+	}
 	review.DecidedBy = identity.ID
 	review.DecidedAt = timestamp
 	review.CourtReference = courtReference
 
-	reviewJSON, _ = review.ToJSON()
-	ctx.GetStub().PutState(reviewID, reviewJSON)
+	reviewJSON, err = review.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(reviewID, reviewJSON); err != nil {
+		return err
+	}
 
 	// Update evidence status
-	evidence, err := s.GetEvidence(ctx, review.EvidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, review.EvidenceID)
 	if err != nil {
 		return err
 	}
 
+	if err := RequireNotCompromised(evidence); err != nil {
+		return err
+	}
+	targetStatus := StatusRejected
 	if decision == "ADMITTED" {
-		evidence.Status = StatusAdmitted
-	} else {
-		evidence.Status = StatusRejected
+		targetStatus = StatusAdmitted
+	}
+	if err := ValidateStatusTransition(ctx, identity, evidence.Status, targetStatus); err != nil {
+		return err
 	}
+	evidence.Status = targetStatus
 	evidence.UpdatedAt = timestamp
-	evidenceJSON, _ := evidence.ToJSON()
-	ctx.GetStub().PutState(review.EvidenceID, evidenceJSON)
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", review.EvidenceID, timestamp),
 		EvidenceID:    review.EvidenceID,
 		EventType:     EventJudicialDecision,
 		FromEntity:    identity.ID,
@@ -759,12 +913,11 @@ func (s *EvidenceContract) RecordJudicialDecision(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", review.EvidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
 
 	// Emit event
 	eventPayload, _ := json.Marshal(map[string]interface{}{
@@ -794,7 +947,7 @@ func (s *EvidenceContract) AddTag(
 		return err
 	}
 
-	evidence, err := s.GetEvidence(ctx, evidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
 	if err != nil {
 		return err
 	}
@@ -806,17 +959,20 @@ func (s *EvidenceContract) AddTag(
 		}
 	}
 
-	timestamp := time.Now().Unix()
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	evidence.Tags = append(evidence.Tags, tag)
 	evidence.UpdatedAt = timestamp
 
-	evidenceJSON, _ := evidence.ToJSON()
-	ctx.GetStub().PutState(evidenceID, evidenceJSON)
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
 		EvidenceID:    evidenceID,
 		EventType:     EventTagAdded,
 		FromEntity:    identity.ID,
@@ -827,17 +983,17 @@ func (s *EvidenceContract) AddTag(
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
 
 	return nil
 }
 
 // UpdateStatus updates the status of evidence (with validation)
+// Evidence is disposed of with DisposeEvidence, never through UpdateStatus.
 func (s *EvidenceContract) UpdateStatus(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
@@ -849,50 +1005,72 @@ func (s *EvidenceContract) UpdateStatus(
 		return err
 	}
 
-	evidence, err := s.GetEvidence(ctx, evidenceID)
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
 	if err != nil {
 		return err
 	}
 
 	targetStatus := EvidenceStatus(newStatus)
-	if err := ValidateStatusTransition(evidence.Status, targetStatus); err != nil {
+	if evidence.Status == StatusCompromised || targetStatus == StatusCompromised {
+		return fmt.Errorf("%s is only entered by a failed integrity check and left through ResolveIntegrityFailure", StatusCompromised)
+	}
+	if targetStatus == StatusDisposed {
+		return fmt.Errorf("evidence is disposed of with DisposeEvidence, which issues a disposal certificate")
+	}
+	if err := ValidateStatusTransition(ctx, identity, evidence.Status, targetStatus); err != nil {
 		return err
 	}
 
-	timestamp := time.Now().Unix()
+	return applyStatusChange(ctx, identity, evidence, targetStatus, reason)
+}
+
+// applyStatusChange stores a validated status change and records its custody event
+func applyStatusChange(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	targetStatus EvidenceStatus,
+	reason string,
+) error {
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	oldStatus := evidence.Status
 	evidence.Status = targetStatus
 	evidence.UpdatedAt = timestamp
 
-	evidenceJSON, _ := evidence.ToJSON()
-	ctx.GetStub().PutState(evidenceID, evidenceJSON)
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
 
 	// Record event
 	event := CustodyEvent{
 		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
-		EvidenceID:    evidenceID,
+		EvidenceID:    evidence.ID,
 		EventType:     EventStatusChange,
 		FromEntity:    identity.ID,
 		FromOrg:       identity.MSPID,
 		Reason:        reason,
-		Details:       fmt.Sprintf(`{"oldStatus":"%s","newStatus":"%s"}`, oldStatus, newStatus),
+		Details:       fmt.Sprintf(`{"oldStatus":"%s","newStatus":"%s"}`, oldStatus, targetStatus),
 		Timestamp:     timestamp,
 		PerformedBy:   identity.ID,
 		PerformerOrg:  identity.MSPID,
 		PerformerRole: identity.Role,
 		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
 	}
 
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-// VerifyIntegrity verifies evidence integrity against stored hash
+// VerifyIntegrity verifies evidence integrity against the registered digests
+// providedHash is a digest string for one or more registered algorithms; at
+// least one must be strong, and all given must match. The check is attested
+// with method UNSPECIFIED; use AttestIntegrity to record the method and tool.
 func (s *EvidenceContract) VerifyIntegrity(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
@@ -903,44 +1081,12 @@ func (s *EvidenceContract) VerifyIntegrity(
 		return false, err
 	}
 
-	evidence, err := s.GetEvidence(ctx, evidenceID)
+	attestation, err := verifyEvidenceDigests(ctx, identity, evidenceID, providedHash, VerifyUnspecified, "")
 	if err != nil {
 		return false, err
 	}
 
-	verified := evidence.EvidenceHash == providedHash
-	timestamp := time.Now().Unix()
-
-	evidence.IntegrityVerified = verified
-	evidence.LastVerifiedAt = timestamp
-	evidence.UpdatedAt = timestamp
-
-	evidenceJSON, _ := evidence.ToJSON()
-	ctx.GetStub().PutState(evidenceID, evidenceJSON)
-
-	// Record verification event
-	event := CustodyEvent{
-		DocType:       DocTypeCustodyEvent,
-		EventID:       fmt.Sprintf("EVT-%s-%d", evidenceID, timestamp),
-		EvidenceID:    evidenceID,
-		EventType:     EventVerification,
-		FromEntity:    identity.ID,
-		FromOrg:       identity.MSPID,
-		Reason:        "Integrity verification",
-		Details:       fmt.Sprintf(`{"verified":%t,"providedHash":"%s"}`, verified, providedHash[:16]+"..."),
-		Timestamp:     timestamp,
-		PerformedBy:   identity.ID,
-		PerformerOrg:  identity.MSPID,
-		PerformerRole: identity.Role,
-		TxID:          ctx.GetStub().GetTxID(),
-		Verified:      true,
-	}
-
-	eventJSON, _ := event.ToJSON()
-	eventKey := fmt.Sprintf("EVENT~%s~%d", evidenceID, timestamp)
-	ctx.GetStub().PutState(eventKey, eventJSON)
-
-	return verified, nil
+	return attestation.Verified, nil
 }
 
 // =============================================================================
@@ -948,16 +1094,36 @@ func (s *EvidenceContract) VerifyIntegrity(
 // =============================================================================
 
 // GetEvidence retrieves evidence by ID
+// The IPFS location and encryption key reference are only returned to the
+// current custodian or to holders of an unexpired access grant.
 func (s *EvidenceContract) GetEvidence(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
 ) (*Evidence, error) {
 	// Verify view permission
-	_, err := RequirePermission(ctx, PermViewEvidence)
+	identity, err := RequirePermission(ctx, PermViewEvidence)
 	if err != nil {
 		return nil, err
 	}
 
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyContentAccess(ctx, identity, evidence); err != nil {
+		return nil, err
+	}
+
+	return evidence, nil
+}
+
+// getEvidenceState reads evidence from world state without permission checks or redaction
+// Transactions that update the evidence record must use this, never GetEvidence.
+func getEvidenceState(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*Evidence, error) {
 	evidenceJSON, err := ctx.GetStub().GetState(evidenceID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read evidence: %v", err)
@@ -986,80 +1152,67 @@ func (s *EvidenceContract) EvidenceExists(
 	return evidenceJSON != nil, nil
 }
 
-// GetEvidenceHistory retrieves the full custody chain for evidence
+// GetEvidenceHistory retrieves the full custody chain for evidence, in sequence order
 func (s *EvidenceContract) GetEvidenceHistory(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
 ) ([]CustodyEvent, error) {
-	_, err := RequirePermission(ctx, PermViewAudit)
+	identity, err := RequirePermission(ctx, PermViewAudit)
 	if err != nil {
 		return nil, err
 	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
 
-	// Query all events for this evidence
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s","evidenceId":"%s"}}`, DocTypeCustodyEvent, evidenceID)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	events, err := getSequencedEvents(ctx, evidenceID)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	var events []CustodyEvent
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
 
-		var event CustodyEvent
-		if err := json.Unmarshal(queryResult.Value, &event); err != nil {
-			continue
-		}
-		events = append(events, event)
+	// Mark events covered by a valid hash chain
+	verification, err := verifyCustodyChain(ctx, evidenceID)
+	if err != nil {
+		return nil, err
 	}
-
-	// Sort by timestamp
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].Timestamp < events[j].Timestamp
-	})
+	markVerifiedEvents(events, verification)
 
 	return events, nil
 }
 
-// GetEvidenceByCase retrieves all evidence for a case
+// GetEvidenceByCase retrieves a case header and all evidence registered under it
+// Evidence registered before cases were ledger records is returned with a nil case.
 func (s *EvidenceContract) GetEvidenceByCase(
 	ctx contractapi.TransactionContextInterface,
 	caseID string,
-) ([]Evidence, error) {
-	_, err := RequirePermission(ctx, PermViewEvidence)
+) (*CaseEvidence, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
 	if err != nil {
 		return nil, err
 	}
 
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s","caseId":"%s"}}`, DocTypeEvidence, caseID)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	c, err := getCase(ctx, caseID)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	var evidenceList []Evidence
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
+	if c != nil {
+		if err := RequireCaseAttributes(identity, c); err != nil {
 			return nil, err
 		}
+	}
 
-		var evidence Evidence
-		if err := json.Unmarshal(queryResult.Value, &evidence); err != nil {
-			continue
-		}
-		evidenceList = append(evidenceList, evidence)
+	evidenceList, err := getIndexedEvidence(ctx, identity, idxCaseEvidence, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil && len(evidenceList) == 0 {
+		return nil, fmt.Errorf("case %s not found", caseID)
 	}
 
-	return evidenceList, nil
+	return &CaseEvidence{
+		Case:     c,
+		Evidence: evidenceList,
+	}, nil
 }
 
 // QueryByStatus retrieves evidence by status
@@ -1067,34 +1220,12 @@ func (s *EvidenceContract) QueryByStatus(
 	ctx contractapi.TransactionContextInterface,
 	status string,
 ) ([]Evidence, error) {
-	_, err := RequirePermission(ctx, PermViewEvidence)
-	if err != nil {
-		return nil, err
-	}
-
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s","status":"%s"}}`, DocTypeEvidence, status)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	identity, err := RequirePermission(ctx, PermViewEvidence)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var evidenceList []Evidence
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var evidence Evidence
-		if err := json.Unmarshal(queryResult.Value, &evidence); err != nil {
-			continue
-		}
-		evidenceList = append(evidenceList, evidence)
-	}
-
-	return evidenceList, nil
+	return getIndexedEvidence(ctx, identity, idxStatusEvidence, status)
 }
 
 // GetAnalysisRecords retrieves all analysis records for evidence
@@ -1102,34 +1233,15 @@ func (s *EvidenceContract) GetAnalysisRecords(
 	ctx contractapi.TransactionContextInterface,
 	evidenceID string,
 ) ([]AnalysisRecord, error) {
-	_, err := RequirePermission(ctx, PermViewAudit)
+	identity, err := RequirePermission(ctx, PermViewAudit)
 	if err != nil {
 		return nil, err
 	}
-
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s","evidenceId":"%s"}}`, DocTypeAnalysisRecord, evidenceID)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
-	if err != nil {
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var records []AnalysisRecord
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var record AnalysisRecord
-		if err := json.Unmarshal(queryResult.Value, &record); err != nil {
-			continue
-		}
-		records = append(records, record)
-	}
-
-	return records, nil
+	return getIndexedAnalysisRecords(ctx, evidenceID)
 }
 
 // GenerateAuditReport generates a comprehensive audit report
@@ -1154,6 +1266,12 @@ func (s *EvidenceContract) GenerateAuditReport(
 		return nil, err
 	}
 
+	// Verify custody hash chain
+	chainVerification, err := verifyCustodyChain(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get analysis records
 	analysisRecords, err := s.GetAnalysisRecords(ctx, evidenceID)
 	if err != nil {
@@ -1161,40 +1279,29 @@ func (s *EvidenceContract) GenerateAuditReport(
 	}
 
 	// Get judicial reviews
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s","evidenceId":"%s"}}`, DocTypeJudicialReview, evidenceID)
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	judicialReviews, err := getIndexedJudicialReviews(ctx, evidenceID)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var judicialReviews []JudicialReview
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
-			continue
-		}
-		var review JudicialReview
-		if err := json.Unmarshal(queryResult.Value, &review); err != nil {
-			continue
-		}
-		judicialReviews = append(judicialReviews, review)
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	timestamp := time.Now().Unix()
-	reportID := fmt.Sprintf("RPT-%s-%d", evidenceID, timestamp)
+	reportID := GenerateID(ctx, "RPT", evidenceID)
 
 	// Create report
 	report := AuditReport{
-		ReportID:        reportID,
-		EvidenceID:      evidenceID,
-		Evidence:        *evidence,
-		CustodyChain:    custodyChain,
-		AnalysisRecords: analysisRecords,
-		JudicialReviews: judicialReviews,
-		GeneratedAt:     timestamp,
-		GeneratedBy:     identity.ID,
-		Verified:        evidence.IntegrityVerified,
+		ReportID:          reportID,
+		EvidenceID:        evidenceID,
+		Evidence:          *evidence,
+		CustodyChain:      custodyChain,
+		AnalysisRecords:   analysisRecords,
+		JudicialReviews:   judicialReviews,
+		GeneratedAt:       timestamp,
+		GeneratedBy:       identity.ID,
+		ChainVerification: chainVerification,
+		Verified:          evidence.IntegrityVerified && chainVerification.Valid,
 	}
 
 	// Generate integrity hash of report
@@ -1209,33 +1316,11 @@ func (s *EvidenceContract) GenerateAuditReport(
 func (s *EvidenceContract) GetAllEvidence(
 	ctx contractapi.TransactionContextInterface,
 ) ([]Evidence, error) {
-	_, err := RequirePermission(ctx, PermViewEvidence)
-	if err != nil {
-		return nil, err
-	}
-
-	queryString := fmt.Sprintf(`{"selector":{"docType":"%s"}}`, DocTypeEvidence)
-	
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	identity, err := RequirePermission(ctx, PermViewEvidence)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
-	var evidenceList []Evidence
-	for resultsIterator.HasNext() {
-		queryResult, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var evidence Evidence
-		if err := json.Unmarshal(queryResult.Value, &evidence); err != nil {
-			continue
-		}
-		evidenceList = append(evidenceList, evidence)
-	}
-
-	return evidenceList, nil
+	// Every evidence record has exactly one status index key
+	return getIndexedEvidence(ctx, identity, idxStatusEvidence)
 }
-
@@ -0,0 +1,413 @@
+// Copyright Evidentia Chain-of-Custody System
+// Custody event storage, sequencing and hash chaining
+//
+// Design Decision: Events used to be keyed by EVENT~<evidenceID>~<unix seconds>,
+// so two events for the same evidence within one second overwrote each other.
+// Each evidence item now keeps a monotonic sequence counter, and events are
+// stored under composite keys that include both the sequence and the TxID.
+// Every event also carries the hash of its predecessor, so editing or removing
+// an event in a copy of the state DB breaks the chain. Events still under the
+// old keys are moved onto the chain by RebuildIndexes (see migrateLegacyEvents).
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Composite key object types for custody events
+const (
+	eventKeyType        = "EVENT"
+	eventCounterKeyType = "EVENTSEQ"
+)
+
+// EvidenceTransactionContext is the transaction context of EvidenceContract
+// contractapi creates one per transaction, so it can remember which evidence
+// items were already given a custody event in the transaction.
+type EvidenceTransactionContext struct {
+	contractapi.TransactionContext
+	eventEvidence map[string]bool
+}
+
+// claimCustodyEvent reserves the transaction's custody event for an evidence item
+// Returns false if the item already has one.
+func (c *EvidenceTransactionContext) claimCustodyEvent(evidenceID string) bool {
+	if c.eventEvidence == nil {
+		c.eventEvidence = map[string]bool{}
+	}
+	if c.eventEvidence[evidenceID] {
+		return false
+	}
+	c.eventEvidence[evidenceID] = true
+	return true
+}
+
+// formatSequence zero-pads a sequence number so composite keys sort numerically
+func formatSequence(seq uint64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+// getEventCounter reads the event counter for an evidence item (zero value if none)
+func getEventCounter(ctx contractapi.TransactionContextInterface, evidenceID string) (*EventCounter, string, error) {
+	counterKey, err := ctx.GetStub().CreateCompositeKey(eventCounterKeyType, []string{evidenceID})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create event counter key: %v", err)
+	}
+
+	counterJSON, err := ctx.GetStub().GetState(counterKey)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read event counter: %v", err)
+	}
+
+	counter := EventCounter{
+		DocType:    DocTypeEventCounter,
+		EvidenceID: evidenceID,
+	}
+	if counterJSON != nil {
+		if err := json.Unmarshal(counterJSON, &counter); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return &counter, counterKey, nil
+}
+
+// computeEventHash hashes the event contents, including the link to the previous event
+// EventHash itself, Verified and BlockNumber are excluded: they are either the
+// result of the hash or derived after the event was written.
+func computeEventHash(event *CustodyEvent) (string, error) {
+	content := *event
+	content.EventHash = ""
+	content.Verified = false
+	content.BlockNumber = 0
+	return HashJSON(content)
+}
+
+// recordCustodyEvent assigns the next sequence number to an event, links it to
+// the previous event's hash and stores it under EVENT~<evidenceID>~<sequence>~<txID>
+// Fabric does not expose a transaction's own pending writes, so a second event
+// for the same evidence would reuse the sequence and overwrite the first; the
+// EvidenceTransactionContext refuses it instead.
+func recordCustodyEvent(ctx contractapi.TransactionContextInterface, event *CustodyEvent) error {
+	if txCtx, ok := ctx.(*EvidenceTransactionContext); ok && !txCtx.claimCustodyEvent(event.EvidenceID) {
+		return fmt.Errorf("evidence %s already has a custody event in transaction %s", event.EvidenceID, ctx.GetStub().GetTxID())
+	}
+
+	counter, counterKey, err := getEventCounter(ctx, event.EvidenceID)
+	if err != nil {
+		return err
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	counter.LastSequence++
+	counter.LastTxID = txID
+
+	event.Sequence = counter.LastSequence
+	event.EventID = GenerateID(ctx, "EVT", event.EvidenceID, strconv.FormatUint(event.Sequence, 10))
+	event.PreviousHash = counter.LastEventHash
+	event.Verified = false
+	event.EventHash, err = computeEventHash(event)
+	if err != nil {
+		return err
+	}
+	counter.LastEventHash = event.EventHash
+
+	eventKey, err := ctx.GetStub().CreateCompositeKey(eventKeyType, []string{event.EvidenceID, formatSequence(event.Sequence), txID})
+	if err != nil {
+		return fmt.Errorf("failed to create event key: %v", err)
+	}
+
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(eventKey, eventJSON); err != nil {
+		return fmt.Errorf("failed to store custody event: %v", err)
+	}
+
+	counterJSON, err := json.Marshal(counter)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(counterKey, counterJSON); err != nil {
+		return fmt.Errorf("failed to update event counter: %v", err)
+	}
+
+	return nil
+}
+
+// storedEvent is a custody event together with the world state key it is stored under
+type storedEvent struct {
+	Key   string
+	Event CustodyEvent
+}
+
+// getStoredEvents returns all sequenced custody events of an evidence item and their keys in sequence order
+func getStoredEvents(ctx contractapi.TransactionContextInterface, evidenceID string) ([]storedEvent, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(eventKeyType, []string{evidenceID})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var stored []storedEvent
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var event CustodyEvent
+		if err := json.Unmarshal(queryResult.Value, &event); err != nil {
+			return nil, fmt.Errorf("failed to parse custody event %s: %v", queryResult.Key, err)
+		}
+		stored = append(stored, storedEvent{Key: queryResult.Key, Event: event})
+	}
+
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].Event.Sequence < stored[j].Event.Sequence
+	})
+
+	return stored, nil
+}
+
+// getSequencedEvents returns all sequenced custody events of an evidence item in sequence order
+func getSequencedEvents(ctx contractapi.TransactionContextInterface, evidenceID string) ([]CustodyEvent, error) {
+	stored, err := getStoredEvents(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []CustodyEvent
+	for _, s := range stored {
+		events = append(events, s.Event)
+	}
+	return events, nil
+}
+
+// migrateLegacyEvents moves custody events stored under the pre-sequencing
+// EVENT~<evidenceID>~<unix seconds> simple keys onto the sequenced chain
+// legacy maps evidence IDs to the legacy events RebuildIndexes found for them.
+// An item's legacy events come first, in timestamp order, followed by any events
+// sequenced since the upgrade; the whole chain is renumbered and re-hashed, the
+// old keys are deleted and the event counter is set to the new head. Returns
+// the number of legacy events migrated.
+func migrateLegacyEvents(ctx contractapi.TransactionContextInterface, legacy map[string][]storedEvent) (int, error) {
+	evidenceIDs := make([]string, 0, len(legacy))
+	for evidenceID := range legacy {
+		evidenceIDs = append(evidenceIDs, evidenceID)
+	}
+	sort.Strings(evidenceIDs)
+
+	migrated := 0
+	for _, evidenceID := range evidenceIDs {
+		chain := legacy[evidenceID]
+		sort.SliceStable(chain, func(i, j int) bool {
+			if chain[i].Event.Timestamp != chain[j].Event.Timestamp {
+				return chain[i].Event.Timestamp < chain[j].Event.Timestamp
+			}
+			return chain[i].Key < chain[j].Key
+		})
+		migrated += len(chain)
+
+		sequenced, err := getStoredEvents(ctx, evidenceID)
+		if err != nil {
+			return 0, err
+		}
+		chain = append(chain, sequenced...)
+
+		counter, counterKey, err := getEventCounter(ctx, evidenceID)
+		if err != nil {
+			return 0, err
+		}
+		counter.LastEventHash = ""
+
+		// Keys written below are overwritten rather than deleted
+		newKeys := make(map[string][]byte, len(chain))
+		for i := range chain {
+			event := &chain[i].Event
+			event.Sequence = uint64(i + 1)
+			event.PreviousHash = counter.LastEventHash
+			event.Verified = false
+			event.EventHash, err = computeEventHash(event)
+			if err != nil {
+				return 0, err
+			}
+
+			txID := event.TxID
+			if txID == "" {
+				txID = ctx.GetStub().GetTxID()
+			}
+			eventKey, err := ctx.GetStub().CreateCompositeKey(eventKeyType, []string{evidenceID, formatSequence(event.Sequence), txID})
+			if err != nil {
+				return 0, fmt.Errorf("failed to create event key: %v", err)
+			}
+			eventJSON, err := event.ToJSON()
+			if err != nil {
+				return 0, err
+			}
+			newKeys[eventKey] = eventJSON
+
+			counter.LastSequence = event.Sequence
+			counter.LastTxID = txID
+			counter.LastEventHash = event.EventHash
+		}
+
+		for _, s := range chain {
+			if _, rewritten := newKeys[s.Key]; rewritten {
+				continue
+			}
+			if err := ctx.GetStub().DelState(s.Key); err != nil {
+				return 0, fmt.Errorf("failed to delete custody event %s: %v", s.Key, err)
+			}
+		}
+		for eventKey, eventJSON := range newKeys {
+			if err := ctx.GetStub().PutState(eventKey, eventJSON); err != nil {
+				return 0, fmt.Errorf("failed to store custody event: %v", err)
+			}
+		}
+
+		counterJSON, err := json.Marshal(counter)
+		if err != nil {
+			return 0, err
+		}
+		if err := ctx.GetStub().PutState(counterKey, counterJSON); err != nil {
+			return 0, fmt.Errorf("failed to update event counter: %v", err)
+		}
+	}
+
+	return migrated, nil
+}
+
+// GetEventSequenceGaps reports custody event sequence numbers with no stored event
+func (s *EvidenceContract) GetEventSequenceGaps(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*SequenceGapReport, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	counter, _, err := getEventCounter(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := getSequencedEvents(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint64]bool, len(events))
+	for _, event := range events {
+		seen[event.Sequence] = true
+	}
+
+	missing := []uint64{}
+	for seq := uint64(1); seq <= counter.LastSequence; seq++ {
+		if !seen[seq] {
+			missing = append(missing, seq)
+		}
+	}
+
+	return &SequenceGapReport{
+		EvidenceID:       evidenceID,
+		LastSequence:     counter.LastSequence,
+		EventCount:       len(events),
+		MissingSequences: missing,
+		Complete:         len(missing) == 0,
+	}, nil
+}
+
+// verifyCustodyChain recomputes the hash chain of an evidence item and reports the first broken link
+func verifyCustodyChain(ctx contractapi.TransactionContextInterface, evidenceID string) (*ChainVerificationResult, error) {
+	counter, _, err := getEventCounter(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := getSequencedEvents(ctx, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChainVerificationResult{
+		EvidenceID: evidenceID,
+		EventCount: len(events),
+		Valid:      true,
+	}
+
+	broken := func(seq uint64, eventID string, reason string) *ChainVerificationResult {
+		result.Valid = false
+		result.BrokenAtSequence = seq
+		result.BrokenEventID = eventID
+		result.Reason = reason
+		return result
+	}
+
+	previousHash := ""
+	for i := range events {
+		event := &events[i]
+		expectedSeq := uint64(i + 1)
+
+		if event.Sequence != expectedSeq {
+			return broken(expectedSeq, "", fmt.Sprintf("event with sequence %d is missing", expectedSeq)), nil
+		}
+		if event.PreviousHash != previousHash {
+			return broken(event.Sequence, event.EventID, "previous hash does not match the preceding event"), nil
+		}
+
+		hash, err := computeEventHash(event)
+		if err != nil {
+			return nil, err
+		}
+		if hash != event.EventHash {
+			return broken(event.Sequence, event.EventID, "event contents do not match the stored event hash"), nil
+		}
+
+		previousHash = event.EventHash
+		result.HeadHash = event.EventHash
+	}
+
+	if uint64(len(events)) != counter.LastSequence {
+		return broken(uint64(len(events))+1, "", fmt.Sprintf("counter reports %d events but %d are stored", counter.LastSequence, len(events))), nil
+	}
+	if previousHash != counter.LastEventHash {
+		return broken(counter.LastSequence, "", "chain head does not match the recorded head hash"), nil
+	}
+
+	return result, nil
+}
+
+// markVerifiedEvents sets Verified on every sequenced event that precedes the first broken link
+func markVerifiedEvents(events []CustodyEvent, result *ChainVerificationResult) {
+	for i := range events {
+		seq := events[i].Sequence
+		events[i].Verified = seq > 0 && (result.Valid || seq < result.BrokenAtSequence)
+	}
+}
+
+// VerifyCustodyChain recomputes the custody hash chain of an evidence item
+func (s *EvidenceContract) VerifyCustodyChain(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*ChainVerificationResult, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	return verifyCustodyChain(ctx, evidenceID)
+}
@@ -0,0 +1,419 @@
+// Copyright Evidentia Chain-of-Custody System
+// Two-phase custody handover (initiate / accept / reject / cancel)
+//
+// Design Decision: Custody only moves once the receiving identity acknowledges
+// receipt. While a handover is pending the evidence is marked in transit and no
+// other transfer can be started.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// applyCustodyChange moves custody to a new entity and advances the status
+// The status only advances if the lifecycle allows the identity completing the
+// handover to make that transition; custody passing to a LAB organization moves
+// evidence into analysis. Returns the status before the change.
+func applyCustodyChange(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	toEntityID string,
+	toOrgMSP string,
+	timestamp int64,
+) (EvidenceStatus, error) {
+	previousStatus := evidence.Status
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	evidence.CurrentCustodian = toEntityID
+	evidence.CurrentOrg = toOrgMSP
+	evidence.UpdatedAt = timestamp
+
+	// Update status if transitioning to analysis
+	nextStatus := evidence.Status
+	if policy.OrgType(toOrgMSP) == OrgLab && evidence.Status == StatusInCustody {
+		nextStatus = StatusInAnalysis
+	} else if evidence.Status == StatusRegistered {
+		nextStatus = StatusInCustody
+	}
+	if nextStatus != evidence.Status && ValidateStatusTransition(ctx, identity, evidence.Status, nextStatus) == nil {
+		evidence.Status = nextStatus
+	}
+
+	return previousStatus, nil
+}
+
+// getCustodyTransfer reads a custody transfer from world state
+func getCustodyTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*CustodyTransfer, error) {
+	transferJSON, err := ctx.GetStub().GetState(transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transferJSON == nil {
+		return nil, fmt.Errorf("transfer %s not found", transferID)
+	}
+
+	var transfer CustodyTransfer
+	if err := json.Unmarshal(transferJSON, &transfer); err != nil {
+		return nil, err
+	}
+	if transfer.DocType != DocTypeCustodyTransfer {
+		return nil, fmt.Errorf("%s is not a custody transfer", transferID)
+	}
+
+	return &transfer, nil
+}
+
+// resolveTransfer closes a pending transfer and clears the in-transit marker on the evidence
+func resolveTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transfer *CustodyTransfer,
+	evidence *Evidence,
+	status TransferStatus,
+	identity *ClientIdentity,
+	note string,
+	timestamp int64,
+) error {
+	transfer.Status = status
+	transfer.ResolvedBy = identity.ID
+	transfer.ResolvedAt = timestamp
+	transfer.ResolutionNote = note
+
+	transferJSON, err := transfer.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(transfer.TransferID, transferJSON); err != nil {
+		return err
+	}
+
+	evidence.InTransit = false
+	evidence.PendingTransferID = ""
+	evidence.UpdatedAt = timestamp
+
+	return putEvidence(ctx, evidence)
+}
+
+// loadPendingTransfer reads a transfer and its evidence, checking the transfer is still pending
+func loadPendingTransfer(ctx contractapi.TransactionContextInterface, transferID string) (*CustodyTransfer, *Evidence, error) {
+	transfer, err := getCustodyTransfer(ctx, transferID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transfer.Status != TransferPending {
+		return nil, nil, fmt.Errorf("transfer %s is not pending, current status: %s", transferID, transfer.Status)
+	}
+
+	evidence, err := getEvidenceState(ctx, transfer.EvidenceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !evidence.InTransit || evidence.PendingTransferID != transferID {
+		return nil, nil, fmt.Errorf("evidence %s is not in transit under transfer %s", transfer.EvidenceID, transferID)
+	}
+
+	return transfer, evidence, nil
+}
+
+// requireReceiver checks the caller is the receiving identity of a transfer
+func requireReceiver(identity *ClientIdentity, transfer *CustodyTransfer) error {
+	if identity.ID != transfer.ToEntity || identity.MSPID != transfer.ToOrg {
+		return fmt.Errorf("only the receiving identity %s (%s) can resolve transfer %s", transfer.ToEntity, transfer.ToOrg, transfer.TransferID)
+	}
+	return nil
+}
+
+// InitiateTransfer starts a custody handover that the receiver must accept
+// A handover to another organization waits for multi-party approval when the
+// policy requires it; the ID of the PendingAction is then returned instead of
+// a transfer ID, and the transfer starts when the last approval is given.
+func (s *EvidenceContract) InitiateTransfer(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	toEntityID string,
+	toOrgMSP string,
+	reason string,
+) (string, error) {
+	identity, err := RequirePermission(ctx, PermTransferCustody)
+	if err != nil {
+		return "", err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return "", err
+	}
+
+	if evidence.InTransit {
+		return "", fmt.Errorf("evidence %s is already in transit under transfer %s", evidenceID, evidence.PendingTransferID)
+	}
+	if err := ValidateCustodyTransfer(ctx, identity, evidence, toOrgMSP); err != nil {
+		return "", err
+	}
+	if toEntityID == "" {
+		return "", fmt.Errorf("receiving identity is required")
+	}
+	if toEntityID == evidence.CurrentCustodian && toOrgMSP == evidence.CurrentOrg {
+		return "", fmt.Errorf("evidence %s is already in the custody of %s", evidenceID, toEntityID)
+	}
+
+	if toOrgMSP != evidence.CurrentOrg {
+		action, err := requireApproval(ctx, identity, ActionCrossOrgTransfer, evidence, map[string]string{
+			"toEntity": toEntityID,
+			"toOrg":    toOrgMSP,
+		}, reason)
+		if err != nil {
+			return "", err
+		}
+		if action != nil {
+			return action.ActionID, nil
+		}
+	}
+
+	return initiateTransfer(ctx, identity, evidence, toEntityID, toOrgMSP, reason)
+}
+
+// initiateTransfer stores a validated handover and puts the evidence in transit
+func initiateTransfer(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	toEntityID string,
+	toOrgMSP string,
+	reason string,
+) (string, error) {
+	evidenceID := evidence.ID
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+	transferID := GenerateID(ctx, "TRF", evidenceID)
+
+	transfer := CustodyTransfer{
+		DocType:     DocTypeCustodyTransfer,
+		TransferID:  transferID,
+		EvidenceID:  evidenceID,
+		FromEntity:  evidence.CurrentCustodian,
+		FromOrg:     evidence.CurrentOrg,
+		ToEntity:    toEntityID,
+		ToOrg:       toOrgMSP,
+		Reason:      reason,
+		Status:      TransferPending,
+		InitiatedBy: identity.ID,
+		InitiatedAt: timestamp,
+	}
+
+	transferJSON, err := transfer.ToJSON()
+	if err != nil {
+		return "", err
+	}
+	if err := ctx.GetStub().PutState(transferID, transferJSON); err != nil {
+		return "", err
+	}
+
+	evidence.InTransit = true
+	evidence.PendingTransferID = transferID
+	evidence.UpdatedAt = timestamp
+
+	if err := putEvidence(ctx, evidence); err != nil {
+		return "", err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventTransferInitiated,
+		FromEntity:    transfer.FromEntity,
+		FromOrg:       transfer.FromOrg,
+		ToEntity:      toEntityID,
+		ToOrg:         toOrgMSP,
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"transferId":"%s"}`, transferID),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return "", err
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       "TRANSFER_INITIATED",
+		"evidenceId": evidenceID,
+		"transferId": transferID,
+		"to":         toEntityID,
+		"toOrg":      toOrgMSP,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent("TransferInitiated", eventPayload)
+
+	return transferID, nil
+}
+
+// AcceptTransfer completes a pending handover; only the receiving identity may accept
+func (s *EvidenceContract) AcceptTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	receiptNote string,
+) error {
+	identity, err := RequirePermission(ctx, PermReceiveCustody)
+	if err != nil {
+		return err
+	}
+
+	transfer, evidence, err := loadPendingTransfer(ctx, transferID)
+	if err != nil {
+		return err
+	}
+	if err := requireReceiver(identity, transfer); err != nil {
+		return err
+	}
+	if err := RequireNotCompromised(evidence); err != nil {
+		return err
+	}
+	if err := RequireEvidenceAttributes(ctx, identity, evidence); err != nil {
+		return err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	previousStatus, err := applyCustodyChange(ctx, identity, evidence, transfer.ToEntity, transfer.ToOrg, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := resolveTransfer(ctx, transfer, evidence, TransferAccepted, identity, receiptNote, timestamp); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    transfer.EvidenceID,
+		EventType:     EventTransfer,
+		FromEntity:    transfer.FromEntity,
+		FromOrg:       transfer.FromOrg,
+		ToEntity:      transfer.ToEntity,
+		ToOrg:         transfer.ToOrg,
+		Reason:        transfer.Reason,
+		Details:       fmt.Sprintf(`{"transferId":"%s","previousStatus":"%s","newStatus":"%s"}`, transferID, previousStatus, evidence.Status),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       "CUSTODY_TRANSFERRED",
+		"evidenceId": transfer.EvidenceID,
+		"transferId": transferID,
+		"from":       transfer.FromEntity,
+		"fromOrg":    transfer.FromOrg,
+		"to":         transfer.ToEntity,
+		"toOrg":      transfer.ToOrg,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent("CustodyTransferred", eventPayload)
+
+	return nil
+}
+
+// RejectTransfer refuses a pending handover; custody stays with the sender
+func (s *EvidenceContract) RejectTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermReceiveCustody)
+	if err != nil {
+		return err
+	}
+
+	transfer, evidence, err := loadPendingTransfer(ctx, transferID)
+	if err != nil {
+		return err
+	}
+	if err := requireReceiver(identity, transfer); err != nil {
+		return err
+	}
+
+	return closeTransfer(ctx, transfer, evidence, TransferRejected, EventTransferRejected, identity, reason)
+}
+
+// CancelTransfer withdraws a pending handover; only the initiator or a
+// supervisor of the sending organization may cancel
+func (s *EvidenceContract) CancelTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	reason string,
+) error {
+	identity, err := RequirePermission(ctx, PermTransferCustody)
+	if err != nil {
+		return err
+	}
+
+	transfer, evidence, err := loadPendingTransfer(ctx, transferID)
+	if err != nil {
+		return err
+	}
+	if identity.ID != transfer.InitiatedBy && (identity.Role != RoleSupervisor || identity.MSPID != transfer.FromOrg) {
+		return fmt.Errorf("only the initiator or a supervisor of %s can cancel transfer %s", transfer.FromOrg, transferID)
+	}
+
+	return closeTransfer(ctx, transfer, evidence, TransferCancelled, EventTransferCancelled, identity, reason)
+}
+
+// closeTransfer ends a pending handover without moving custody
+func closeTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transfer *CustodyTransfer,
+	evidence *Evidence,
+	status TransferStatus,
+	eventType EventType,
+	identity *ClientIdentity,
+	reason string,
+) error {
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := resolveTransfer(ctx, transfer, evidence, status, identity, reason, timestamp); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    transfer.EvidenceID,
+		EventType:     eventType,
+		FromEntity:    transfer.FromEntity,
+		FromOrg:       transfer.FromOrg,
+		ToEntity:      transfer.ToEntity,
+		ToOrg:         transfer.ToOrg,
+		Reason:        reason,
+		Details:       fmt.Sprintf(`{"transferId":"%s","status":"%s"}`, transfer.TransferID, status),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
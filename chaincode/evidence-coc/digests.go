@@ -0,0 +1,269 @@
+// Copyright Evidentia Chain-of-Custody System
+// Algorithm-tagged evidence digests
+//
+// Design Decision: Acquisition tools record several digests per image (MD5 and
+// SHA-1 for legacy compatibility, plus SHA-256, SHA-512 or SHA3). Evidence now
+// carries every digest tagged with its algorithm and a declared primary
+// algorithm, whose value is mirrored in EvidenceHash for existing clients. MD5
+// and SHA-1 are accepted alongside a strong digest but never on their own: a
+// digest set must include a strong algorithm, the primary must be strong, and
+// an integrity check must include at least one strong digest.
+//
+// Digest strings use the form "<algorithm>:<hex>", comma separated, with the
+// primary first, e.g. "sha512:ab12...,md5:9f3c...". A bare 64-character hex
+// value is read as SHA-256, the format used before digests were tagged.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hashAlgorithmSpec describes the digest format and strength of an algorithm
+type hashAlgorithmSpec struct {
+	hexLength int
+	weak      bool
+}
+
+// hashAlgorithmSpecs lists every accepted algorithm
+var hashAlgorithmSpecs = map[HashAlgorithm]hashAlgorithmSpec{
+	HashMD5:     {hexLength: 32, weak: true},
+	HashSHA1:    {hexLength: 40, weak: true},
+	HashSHA256:  {hexLength: 64},
+	HashSHA512:  {hexLength: 128},
+	HashSHA3256: {hexLength: 64},
+	HashSHA3512: {hexLength: 128},
+}
+
+// ParseHashAlgorithm resolves an algorithm name, ignoring case and "-"/"_" separators
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(strings.TrimSpace(name)))
+	for alg := range hashAlgorithmSpecs {
+		if strings.ReplaceAll(string(alg), "-", "") == normalized {
+			return alg, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported hash algorithm: %s", name)
+}
+
+// IsWeakHashAlgorithm reports whether an algorithm may only be used alongside a strong one
+func IsWeakHashAlgorithm(alg HashAlgorithm) bool {
+	return hashAlgorithmSpecs[alg].weak
+}
+
+// ValidateDigest checks a digest value has the length and hex encoding of its algorithm
+func ValidateDigest(d Digest) error {
+	spec, ok := hashAlgorithmSpecs[d.Algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported hash algorithm: %s", d.Algorithm)
+	}
+	if len(d.Value) != spec.hexLength {
+		return fmt.Errorf("%s digest must be %d hex characters, got %d", d.Algorithm, spec.hexLength, len(d.Value))
+	}
+	for _, c := range d.Value {
+		if !((c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')) {
+			return fmt.Errorf("%s digest is not hex encoded", d.Algorithm)
+		}
+	}
+	return nil
+}
+
+// ParseDigests parses a digest string into validated digests, primary first
+func ParseDigests(s string) ([]Digest, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("at least one digest is required")
+	}
+
+	// Legacy untagged SHA-256
+	if !strings.Contains(s, ":") {
+		d := Digest{Algorithm: HashSHA256, Value: strings.ToLower(s)}
+		if err := ValidateDigest(d); err != nil {
+			return nil, err
+		}
+		return []Digest{d}, nil
+	}
+
+	var digests []Digest
+	for _, part := range strings.Split(s, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(part), ":")
+		if !found {
+			return nil, fmt.Errorf("digest %q must have the form <algorithm>:<hex>", part)
+		}
+		alg, err := ParseHashAlgorithm(name)
+		if err != nil {
+			return nil, err
+		}
+		d := Digest{Algorithm: alg, Value: strings.ToLower(strings.TrimSpace(value))}
+		if err := ValidateDigest(d); err != nil {
+			return nil, err
+		}
+		digests = append(digests, d)
+	}
+
+	return digests, nil
+}
+
+// parseDigestArgument parses a digest string argument; the first digest is the primary
+func parseDigestArgument(s string) ([]Digest, HashAlgorithm, error) {
+	digests, err := ParseDigests(s)
+	if err != nil {
+		return nil, "", err
+	}
+	return NormalizeDigestSet(digests, digests[0].Algorithm)
+}
+
+// NormalizeDigestSet validates a digest set and resolves its primary algorithm
+// An empty primary defaults to the first strong digest. Each algorithm may
+// appear once, the set must contain a strong digest, and the primary must be strong.
+func NormalizeDigestSet(digests []Digest, primary HashAlgorithm) ([]Digest, HashAlgorithm, error) {
+	if len(digests) == 0 {
+		return nil, "", fmt.Errorf("at least one digest is required")
+	}
+
+	normalized := make([]Digest, 0, len(digests))
+	seen := map[HashAlgorithm]bool{}
+	firstStrong := HashAlgorithm("")
+	for _, d := range digests {
+		alg, err := ParseHashAlgorithm(string(d.Algorithm))
+		if err != nil {
+			return nil, "", err
+		}
+		d = Digest{Algorithm: alg, Value: strings.ToLower(d.Value)}
+		if err := ValidateDigest(d); err != nil {
+			return nil, "", err
+		}
+		if seen[alg] {
+			return nil, "", fmt.Errorf("%s digest given more than once", alg)
+		}
+		seen[alg] = true
+		if firstStrong == "" && !IsWeakHashAlgorithm(alg) {
+			firstStrong = alg
+		}
+		normalized = append(normalized, d)
+	}
+
+	if firstStrong == "" {
+		return nil, "", fmt.Errorf("weak digests (MD5, SHA-1) must be accompanied by a SHA-256, SHA-512 or SHA3 digest")
+	}
+
+	if primary == "" {
+		return normalized, firstStrong, nil
+	}
+	primary, err := ParseHashAlgorithm(string(primary))
+	if err != nil {
+		return nil, "", err
+	}
+	if !seen[primary] {
+		return nil, "", fmt.Errorf("no digest given for primary algorithm %s", primary)
+	}
+	if IsWeakHashAlgorithm(primary) {
+		return nil, "", fmt.Errorf("weak algorithm %s cannot be the primary digest", primary)
+	}
+
+	return normalized, primary, nil
+}
+
+// resolveDescriptorDigests returns the validated digest set of an evidence descriptor
+func resolveDescriptorDigests(descriptor *EvidenceDescriptor) ([]Digest, HashAlgorithm, error) {
+	if len(descriptor.Digests) > 0 {
+		return NormalizeDigestSet(descriptor.Digests, descriptor.PrimaryHashAlgorithm)
+	}
+	return parseDigestArgument(descriptor.EvidenceHash)
+}
+
+// DigestAlgorithms returns the algorithms of a digest set, in order
+func DigestAlgorithms(digests []Digest) []HashAlgorithm {
+	algorithms := make([]HashAlgorithm, 0, len(digests))
+	for _, d := range digests {
+		algorithms = append(algorithms, d.Algorithm)
+	}
+	return algorithms
+}
+
+// DigestFor returns the digest of an algorithm, if the set has one
+func DigestFor(digests []Digest, alg HashAlgorithm) (Digest, bool) {
+	for _, d := range digests {
+		if d.Algorithm == alg {
+			return d, true
+		}
+	}
+	return Digest{}, false
+}
+
+// RegisteredDigests returns the evidence digests, treating records created
+// before digests were tagged as a single SHA-256 digest
+func (e *Evidence) RegisteredDigests() []Digest {
+	if len(e.Digests) > 0 {
+		return e.Digests
+	}
+	return []Digest{{Algorithm: HashSHA256, Value: strings.ToLower(e.EvidenceHash)}}
+}
+
+// SetDigests stores a normalized digest set and mirrors the primary value in EvidenceHash
+func (e *Evidence) SetDigests(digests []Digest, primary HashAlgorithm) {
+	e.Digests = digests
+	e.PrimaryHashAlgorithm = primary
+	if d, ok := DigestFor(digests, primary); ok {
+		e.EvidenceHash = d.Value
+	}
+}
+
+// MatchDigests compares provided digests against the registered ones
+// Every provided digest must use a registered algorithm and match it, and at
+// least one must be strong; a weak match on its own is an error, not a pass.
+// A bare untagged value is compared against the registered digests of its length.
+// Returns the provided digests, tagged with the algorithm each was checked as.
+func MatchDigests(registered []Digest, provided string) (bool, []Digest, error) {
+	provided = strings.TrimSpace(provided)
+
+	var candidates []Digest
+	if provided != "" && !strings.Contains(provided, ":") {
+		value := strings.ToLower(provided)
+		var candidate *Digest
+		for _, d := range registered {
+			if len(d.Value) != len(value) {
+				continue
+			}
+			// Prefer the registered algorithm the value matches
+			if candidate == nil || d.Value == value {
+				candidate = &Digest{Algorithm: d.Algorithm, Value: value}
+			}
+			if d.Value == value {
+				break
+			}
+		}
+		if candidate == nil {
+			return false, nil, fmt.Errorf("no registered digest has %d hex characters", len(value))
+		}
+		candidates = []Digest{*candidate}
+	} else {
+		parsed, err := ParseDigests(provided)
+		if err != nil {
+			return false, nil, err
+		}
+		candidates = parsed
+	}
+
+	strong := false
+	matched := true
+	for _, c := range candidates {
+		d, ok := DigestFor(registered, c.Algorithm)
+		if !ok {
+			return false, nil, fmt.Errorf("no %s digest is registered for this evidence", c.Algorithm)
+		}
+		if !IsWeakHashAlgorithm(c.Algorithm) {
+			strong = true
+		}
+		if d.Value != c.Value {
+			matched = false
+		}
+	}
+
+	if !strong {
+		return false, candidates, fmt.Errorf("weak digests (MD5, SHA-1) cannot be the only integrity proof")
+	}
+
+	return matched, candidates, nil
+}
@@ -0,0 +1,293 @@
+// Copyright Evidentia Chain-of-Custody System
+// Evidence disposal and disposal certificates
+//
+// Design Decision: Disposal used to be a status change through UpdateStatus,
+// leaving no record of how the evidence was destroyed, who witnessed it, or
+// whether the IPFS content was unpinned. DisposeEvidence requires the method,
+// a witness, the legal authority and the disposer's confirmation that every
+// stored copy was destroyed, and issues a DisposalCertificate that carries its
+// own hash. When the policy requires approval of disposal (see approvals.go),
+// the request waits as a PendingAction and the certificate is issued, listing
+// the approvers, once the last approval is given. Disposed evidence is
+// read-only: putEvidence refuses to change it and no lifecycle may leave
+// DISPOSED.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// disposalRequest holds the DisposeEvidence arguments, kept as action parameters while awaiting approval
+type disposalRequest struct {
+	Method         DisposalMethod
+	WitnessID      string
+	LegalAuthority string
+	IPFSUnpinned   bool
+	Notes          string
+}
+
+// params encodes the request for a PendingAction
+func (r *disposalRequest) params() map[string]string {
+	return map[string]string{
+		"method":         string(r.Method),
+		"witnessId":      r.WitnessID,
+		"legalAuthority": r.LegalAuthority,
+		"ipfsUnpinned":   fmt.Sprintf("%t", r.IPFSUnpinned),
+		"notes":          r.Notes,
+	}
+}
+
+// disposalRequestFromParams decodes a request stored with a PendingAction
+func disposalRequestFromParams(params map[string]string) *disposalRequest {
+	return &disposalRequest{
+		Method:         DisposalMethod(params["method"]),
+		WitnessID:      params["witnessId"],
+		LegalAuthority: params["legalAuthority"],
+		IPFSUnpinned:   params["ipfsUnpinned"] == "true",
+		Notes:          params["notes"],
+	}
+}
+
+// parseDisposalMethod resolves a disposal method
+func parseDisposalMethod(method string) (DisposalMethod, error) {
+	switch m := DisposalMethod(strings.ToUpper(strings.TrimSpace(method))); m {
+	case DisposalPhysicalDestruction, DisposalSecureWipe, DisposalCryptoErasure:
+		return m, nil
+	default:
+		return "", fmt.Errorf("disposal method must be %s, %s or %s, got %q",
+			DisposalPhysicalDestruction, DisposalSecureWipe, DisposalCryptoErasure, method)
+	}
+}
+
+// validateDisposal checks evidence can be disposed of by an identity
+func validateDisposal(ctx contractapi.TransactionContextInterface, identity *ClientIdentity, evidence *Evidence) error {
+	if evidence.InTransit {
+		return fmt.Errorf("evidence %s is in transit under transfer %s", evidence.ID, evidence.PendingTransferID)
+	}
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can dispose of evidence %s", evidence.ID)
+	}
+	return ValidateStatusTransition(ctx, identity, evidence.Status, StatusDisposed)
+}
+
+// HashCertificate returns the SHA-256 of a certificate's JSON with CertificateHash empty
+func (c *DisposalCertificate) HashCertificate() (string, error) {
+	unsealed := *c
+	unsealed.CertificateHash = ""
+	certificateJSON, err := unsealed.ToJSON()
+	if err != nil {
+		return "", err
+	}
+	return HashData(certificateJSON), nil
+}
+
+// disposeEvidence issues the disposal certificate and moves evidence to DISPOSED
+// action is the approved PendingAction, or nil if no approval was required.
+func disposeEvidence(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	request *disposalRequest,
+	action *PendingAction,
+) (*DisposalCertificate, error) {
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &DisposalCertificate{
+		DocType:         DocTypeDisposalCertificate,
+		CertificateID:   GenerateID(ctx, "DSP", evidence.ID),
+		EvidenceID:      evidence.ID,
+		CaseID:          evidence.CaseID,
+		EvidenceHash:    evidence.EvidenceHash,
+		IPFSHash:        evidence.IPFSHash,
+		Method:          request.Method,
+		LegalAuthority:  request.LegalAuthority,
+		WitnessID:       request.WitnessID,
+		CopiesDestroyed: true,
+		IPFSUnpinned:    request.IPFSUnpinned,
+		Notes:           request.Notes,
+		DisposedBy:      identity.ID,
+		DisposerOrg:     identity.MSPID,
+		DisposerRole:    identity.Role,
+		DisposedAt:      timestamp,
+		TxID:            ctx.GetStub().GetTxID(),
+	}
+	if action != nil {
+		certificate.ActionID = action.ActionID
+		for _, a := range action.Approvals {
+			certificate.Approvers = append(certificate.Approvers, a.ApproverID)
+		}
+	}
+	certificate.CertificateHash, err = certificate.HashCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	certificateJSON, err := certificate.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(certificate.CertificateID, certificateJSON); err != nil {
+		return nil, fmt.Errorf("failed to store disposal certificate: %v", err)
+	}
+
+	previousStatus := evidence.Status
+	evidence.Status = StatusDisposed
+	evidence.DisposalCertificateID = certificate.CertificateID
+	evidence.UpdatedAt = timestamp
+	if err := putEvidence(ctx, evidence); err != nil {
+		return nil, err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidence.ID,
+		EventType:     EventDisposal,
+		FromEntity:    evidence.CurrentCustodian,
+		FromOrg:       evidence.CurrentOrg,
+		Reason:        request.LegalAuthority,
+		Details:       fmt.Sprintf(`{"certificateId":"%s","certificateHash":"%s","method":"%s","witnessId":"%s","previousStatus":"%s"}`, certificate.CertificateID, certificate.CertificateHash, request.Method, request.WitnessID, previousStatus),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":            "EVIDENCE_DISPOSED",
+		"evidenceId":      evidence.ID,
+		"caseId":          evidence.CaseID,
+		"certificateId":   certificate.CertificateID,
+		"certificateHash": certificate.CertificateHash,
+		"ipfsHash":        certificate.IPFSHash,
+		"disposedBy":      identity.ID,
+		"timestamp":       timestamp,
+	})
+	ctx.GetStub().SetEvent("EvidenceDisposed", eventPayload)
+
+	return certificate, nil
+}
+
+// DisposeEvidence destroys evidence and issues a disposal certificate
+// Parameters:
+//   - method: PHYSICAL_DESTRUCTION, SECURE_WIPE or CRYPTOGRAPHIC_ERASURE
+//   - witnessID: Identity that witnessed the destruction (not the caller)
+//   - legalAuthority: Court order or statute authorizing the disposal
+//   - copiesDestroyed: Confirmation that every stored copy was destroyed (must be true)
+//   - ipfsUnpinned: Confirmation that the IPFS content was unpinned (must be true if the evidence has a CID)
+//   - notes: Optional remarks
+//
+// Returns the certificate ID, or the ID of the PendingAction if disposal needs approval.
+func (s *EvidenceContract) DisposeEvidence(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	method string,
+	witnessID string,
+	legalAuthority string,
+	copiesDestroyed bool,
+	ipfsUnpinned bool,
+	notes string,
+) (string, error) {
+	identity, err := RequirePermission(ctx, PermDisposeEvidence)
+	if err != nil {
+		return "", err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return "", err
+	}
+	if err := validateDisposal(ctx, identity, evidence); err != nil {
+		return "", err
+	}
+
+	disposalMethod, err := parseDisposalMethod(method)
+	if err != nil {
+		return "", err
+	}
+	witnessID = strings.TrimSpace(witnessID)
+	if witnessID == "" {
+		return "", fmt.Errorf("a witness to the disposal is required")
+	}
+	if witnessID == identity.ID {
+		return "", fmt.Errorf("the disposer cannot witness their own disposal")
+	}
+	legalAuthority = strings.TrimSpace(legalAuthority)
+	if legalAuthority == "" {
+		return "", fmt.Errorf("a legal authority for the disposal is required")
+	}
+	if !copiesDestroyed {
+		return "", fmt.Errorf("every stored copy of evidence %s must be destroyed before disposal", evidenceID)
+	}
+	if evidence.IPFSHash != "" && !ipfsUnpinned {
+		return "", fmt.Errorf("IPFS content %s must be unpinned before disposal", evidence.IPFSHash)
+	}
+
+	request := &disposalRequest{
+		Method:         disposalMethod,
+		WitnessID:      witnessID,
+		LegalAuthority: legalAuthority,
+		IPFSUnpinned:   ipfsUnpinned,
+		Notes:          notes,
+	}
+
+	action, err := requireApproval(ctx, identity, ActionDisposal, evidence, request.params(), legalAuthority)
+	if err != nil {
+		return "", err
+	}
+	if action != nil {
+		return action.ActionID, nil
+	}
+
+	certificate, err := disposeEvidence(ctx, identity, evidence, request, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return certificate.CertificateID, nil
+}
+
+// GetDisposalCertificate returns the disposal certificate of a disposed evidence item
+func (s *EvidenceContract) GetDisposalCertificate(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*DisposalCertificate, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if evidence.DisposalCertificateID == "" {
+		return nil, fmt.Errorf("evidence %s has no disposal certificate", evidenceID)
+	}
+
+	certificateJSON, err := ctx.GetStub().GetState(evidence.DisposalCertificateID)
+	if err != nil {
+		return nil, err
+	}
+	if certificateJSON == nil {
+		return nil, fmt.Errorf("disposal certificate %s not found", evidence.DisposalCertificateID)
+	}
+
+	var certificate DisposalCertificate
+	if err := json.Unmarshal(certificateJSON, &certificate); err != nil {
+		return nil, err
+	}
+
+	return &certificate, nil
+}
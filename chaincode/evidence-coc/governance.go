@@ -0,0 +1,729 @@
+// Copyright Evidentia Chain-of-Custody System
+// Governance of the role permission matrix and the organization registry
+//
+// Design Decision: RolePermissions and the organization permissions were compiled
+// in, so granting auditors export rights meant redeploying chaincode. The matrix,
+// the organization registry and the approval rules for high-risk operations
+// (see approvals.go) are now a versioned PermissionPolicy in world state, read
+// by HasPermission.
+// A change is proposed with ProposePolicyChange by an administrator of a member
+// organization (counting as that organization's approval) and adopted once the
+// policy's ApprovalPercent of governing organizations (members that keep
+// MANAGE_LEDGER) have approved it with ApprovePolicyChange. A proposal is tied to the version it was made against,
+// so it cannot be adopted after another change has landed. Every adopted
+// version is kept for audit. Until the first adoption the compiled-in matrix and
+// DefaultOrganizations apply as version 0.
+//
+// The registry records each organization's type, default role and permissions.
+// New members (prosecutors, defence counsel, private labs) are onboarded with
+// ProposeOrganizationChange, and chaincode logic asks for an organization type
+// (OrgType, OrgsOfType) instead of naming MSP IDs.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// permissionPolicyKey holds the policy in force; every adopted version is also
+// kept under a policyVersionObjectType composite key
+const (
+	permissionPolicyKey     = "PERMISSION_POLICY"
+	policyVersionObjectType = "PERMISSION_POLICY_VERSION"
+)
+
+// defaultApprovalPercent is a simple majority of member organizations
+const defaultApprovalPercent = 51
+
+// defaultPermissionPolicy returns the compiled-in matrices as policy version 0
+func defaultPermissionPolicy() *PermissionPolicy {
+	policy := &PermissionPolicy{
+		DocType:         DocTypePermissionPolicy,
+		Version:         0,
+		RolePermissions: map[Role][]Permission{},
+		Organizations:   map[string]OrganizationRecord{},
+		ApprovalRules:   defaultApprovalRules(),
+		ApprovalPercent: defaultApprovalPercent,
+	}
+	for role, perms := range RolePermissions {
+		policy.RolePermissions[role] = append([]Permission{}, perms...)
+	}
+	for msp, org := range DefaultOrganizations {
+		org.Permissions = append([]Permission{}, org.Permissions...)
+		policy.Organizations[msp] = org
+	}
+	return policy
+}
+
+// getPermissionPolicy returns the policy in force, or the built-in default if none is stored
+func getPermissionPolicy(ctx contractapi.TransactionContextInterface) (*PermissionPolicy, error) {
+	policyJSON, err := ctx.GetStub().GetState(permissionPolicyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read permission policy: %v", err)
+	}
+	if policyJSON == nil {
+		return defaultPermissionPolicy(), nil
+	}
+
+	var policy PermissionPolicy
+	if err := json.Unmarshal(policyJSON, &policy); err != nil {
+		return nil, err
+	}
+	// Policies adopted before approval rules existed get the built-in rules
+	if policy.ApprovalRules == nil {
+		policy.ApprovalRules = defaultApprovalRules()
+	}
+
+	return &policy, nil
+}
+
+// Allows reports whether both the identity's role and its organization hold a permission
+func (p *PermissionPolicy) Allows(identity *ClientIdentity, permission Permission) bool {
+	for _, perm := range p.RolePermissions[identity.Role] {
+		if perm == permission {
+			return p.OrgAllows(identity.MSPID, permission)
+		}
+	}
+	return false
+}
+
+// OrgAllows reports whether an organization holds a permission
+func (p *PermissionPolicy) OrgAllows(mspID string, permission Permission) bool {
+	for _, perm := range p.Organizations[mspID].Permissions {
+		if perm == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMember reports whether an organization is a member under this policy
+func (p *PermissionPolicy) IsMember(mspID string) bool {
+	_, ok := p.Organizations[mspID]
+	return ok
+}
+
+// Members returns the member organizations, sorted
+func (p *PermissionPolicy) Members() []string {
+	members := make([]string, 0, len(p.Organizations))
+	for org := range p.Organizations {
+		members = append(members, org)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// Governors returns the member organizations that keep MANAGE_LEDGER, sorted
+// Only they can propose or approve policy changes.
+func (p *PermissionPolicy) Governors() []string {
+	governors := []string{}
+	for _, msp := range p.Members() {
+		if p.OrgAllows(msp, PermManageLedger) {
+			governors = append(governors, msp)
+		}
+	}
+	return governors
+}
+
+// OrgType returns the type of a member organization, or "" if it is not registered
+func (p *PermissionPolicy) OrgType(mspID string) OrgType {
+	return p.Organizations[mspID].Type
+}
+
+// OrgsOfType returns the registered organizations of a type, sorted by MSP ID
+func (p *PermissionPolicy) OrgsOfType(orgType OrgType) []OrganizationRecord {
+	orgs := []OrganizationRecord{}
+	for _, msp := range p.Members() {
+		if p.Organizations[msp].Type == orgType {
+			orgs = append(orgs, p.Organizations[msp])
+		}
+	}
+	return orgs
+}
+
+// CourtFor returns the MSP ID of the court organization that hears cases in a jurisdiction
+// A court registered for the jurisdiction is preferred; otherwise the only
+// registered court is used. It is an error if that leaves no court or several.
+func (p *PermissionPolicy) CourtFor(jurisdiction string) (string, error) {
+	courts := p.OrgsOfType(OrgCourt)
+	if jurisdiction != "" {
+		matching := []OrganizationRecord{}
+		for _, court := range courts {
+			if strings.EqualFold(court.Jurisdiction, jurisdiction) {
+				matching = append(matching, court)
+			}
+		}
+		if len(matching) > 0 {
+			courts = matching
+		}
+	}
+
+	switch len(courts) {
+	case 0:
+		return "", fmt.Errorf("no %s organization is registered", OrgCourt)
+	case 1:
+		return courts[0].MSPID, nil
+	default:
+		return "", fmt.Errorf("several %s organizations could hear jurisdiction %q; register each court's jurisdiction", OrgCourt, jurisdiction)
+	}
+}
+
+// RequiredApprovals returns how many governing organizations must approve a change
+func (p *PermissionPolicy) RequiredApprovals() int {
+	required := (len(p.Governors())*p.ApprovalPercent + 99) / 100
+	if required < 1 {
+		required = 1
+	}
+	return required
+}
+
+// isKnownPermission reports whether a permission is defined by the chaincode
+// Every permission appears in the built-in role matrix.
+func isKnownPermission(permission Permission) bool {
+	for _, perms := range RolePermissions {
+		for _, p := range perms {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isKnownOrgType reports whether an organization type is defined by the chaincode
+func isKnownOrgType(orgType OrgType) bool {
+	switch orgType {
+	case OrgLawEnforcement, OrgLab, OrgCourt, OrgProsecution, OrgDefense:
+		return true
+	}
+	return false
+}
+
+// Validate checks a policy only uses known roles, types and permissions and keeps governance working
+// Administrators and at least one member organization must keep MANAGE_LEDGER,
+// or no further change could be proposed or approved. A registry entry with no
+// MSP ID takes the key it is stored under.
+func (p *PermissionPolicy) Validate() error {
+	if p.ApprovalPercent <= 50 || p.ApprovalPercent > 100 {
+		return fmt.Errorf("approval percentage must be a majority (51-100), got %d", p.ApprovalPercent)
+	}
+	if len(p.Organizations) == 0 {
+		return fmt.Errorf("policy must have at least one member organization")
+	}
+
+	for role, perms := range p.RolePermissions {
+		if _, ok := RolePermissions[role]; !ok {
+			return fmt.Errorf("unknown role %s", role)
+		}
+		for _, perm := range perms {
+			if !isKnownPermission(perm) {
+				return fmt.Errorf("role %s has unknown permission %s", role, perm)
+			}
+		}
+	}
+	for msp, org := range p.Organizations {
+		if strings.TrimSpace(msp) == "" {
+			return fmt.Errorf("organization MSP IDs cannot be empty")
+		}
+		if org.MSPID == "" {
+			org.MSPID = msp
+			p.Organizations[msp] = org
+		}
+		if org.MSPID != msp {
+			return fmt.Errorf("organization %s is registered under MSP ID %s", org.MSPID, msp)
+		}
+		if strings.TrimSpace(org.Name) == "" {
+			return fmt.Errorf("organization %s must have a name", msp)
+		}
+		if !isKnownOrgType(org.Type) {
+			return fmt.Errorf("organization %s has unknown type %q", msp, org.Type)
+		}
+		if _, ok := p.RolePermissions[org.DefaultRole]; !ok {
+			return fmt.Errorf("organization %s has unknown default role %q", msp, org.DefaultRole)
+		}
+		for _, perm := range org.Permissions {
+			if !isKnownPermission(perm) {
+				return fmt.Errorf("organization %s has unknown permission %s", msp, perm)
+			}
+		}
+	}
+	if len(p.Governors()) == 0 {
+		return fmt.Errorf("at least one member organization must keep %s", PermManageLedger)
+	}
+
+	for actionType, rule := range p.ApprovalRules {
+		if err := rule.validate(actionType); err != nil {
+			return err
+		}
+	}
+
+	adminHasLedger := false
+	for _, perm := range p.RolePermissions[RoleAdmin] {
+		if perm == PermManageLedger {
+			adminHasLedger = true
+		}
+	}
+	if !adminHasLedger {
+		return fmt.Errorf("role %s must keep %s to take part in governance", RoleAdmin, PermManageLedger)
+	}
+
+	return nil
+}
+
+// requireGovernanceAdmin checks the caller is an administrator of a member organization
+func requireGovernanceAdmin(ctx contractapi.TransactionContextInterface) (*ClientIdentity, error) {
+	identity, err := RequirePermission(ctx, PermManageLedger)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Role != RoleAdmin {
+		return nil, fmt.Errorf("only administrators of member organizations can take part in policy governance")
+	}
+	return identity, nil
+}
+
+// getPolicyProposal reads a policy proposal from world state
+func getPolicyProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*PolicyProposal, error) {
+	proposalJSON, err := ctx.GetStub().GetState(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposalJSON == nil {
+		return nil, fmt.Errorf("policy proposal %s not found", proposalID)
+	}
+
+	var proposal PolicyProposal
+	if err := json.Unmarshal(proposalJSON, &proposal); err != nil {
+		return nil, err
+	}
+	if proposal.DocType != DocTypePolicyProposal {
+		return nil, fmt.Errorf("%s is not a policy proposal", proposalID)
+	}
+
+	return &proposal, nil
+}
+
+// putPolicyProposal stores a policy proposal
+func putPolicyProposal(ctx contractapi.TransactionContextInterface, proposal *PolicyProposal) error {
+	proposalJSON, err := proposal.ToJSON()
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(proposal.ProposalID, proposalJSON)
+}
+
+// adoptIfApproved puts a proposal's policy in force once enough governing organizations approved it
+// Returns whether the policy was adopted.
+func adoptIfApproved(
+	ctx contractapi.TransactionContextInterface,
+	proposal *PolicyProposal,
+	current *PermissionPolicy,
+	timestamp int64,
+) (bool, error) {
+	approvals := 0
+	for _, a := range proposal.Approvals {
+		if current.OrgAllows(a.Org, PermManageLedger) {
+			approvals++
+		}
+	}
+	if approvals < current.RequiredApprovals() {
+		return false, nil
+	}
+
+	policy := proposal.Policy
+	policy.DocType = DocTypePermissionPolicy
+	policy.Version = current.Version + 1
+	policy.ProposalID = proposal.ProposalID
+	policy.AdoptedAt = timestamp
+	policy.TxID = ctx.GetStub().GetTxID()
+
+	policyJSON, err := policy.ToJSON()
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutState(permissionPolicyKey, policyJSON); err != nil {
+		return false, fmt.Errorf("failed to store permission policy: %v", err)
+	}
+	versionKey, err := ctx.GetStub().CreateCompositeKey(policyVersionObjectType, []string{fmt.Sprintf("%010d", policy.Version)})
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.GetStub().PutState(versionKey, policyJSON); err != nil {
+		return false, fmt.Errorf("failed to store permission policy version: %v", err)
+	}
+
+	proposal.Policy = policy
+	proposal.Status = ProposalAdopted
+	proposal.ResolvedAt = timestamp
+	return true, nil
+}
+
+// emitPolicyEvent raises a chaincode event for a proposal
+func emitPolicyEvent(ctx contractapi.TransactionContextInterface, proposal *PolicyProposal, identity *ClientIdentity, timestamp int64) {
+	eventName, eventType := "PolicyChangeProposed", "POLICY_CHANGE_PROPOSED"
+	if len(proposal.Approvals) > 1 {
+		eventName, eventType = "PolicyChangeApproved", "POLICY_CHANGE_APPROVED"
+	}
+	switch proposal.Status {
+	case ProposalAdopted:
+		eventName, eventType = "PolicyAdopted", "POLICY_ADOPTED"
+	case ProposalWithdrawn:
+		eventName, eventType = "PolicyChangeWithdrawn", "POLICY_CHANGE_WITHDRAWN"
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       eventType,
+		"proposalId": proposal.ProposalID,
+		"version":    proposal.Policy.Version,
+		"approvals":  len(proposal.Approvals),
+		"status":     proposal.Status,
+		"actor":      identity.ID,
+		"actorOrg":   identity.MSPID,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent(eventName, eventPayload)
+}
+
+// proposePolicy validates a proposed policy and stores it as a proposal approved by the caller's organization
+// With a single member organization the policy is adopted at once.
+func proposePolicy(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	current *PermissionPolicy,
+	proposed PermissionPolicy,
+	reason string,
+) (*PolicyProposal, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason for the policy change is required")
+	}
+
+	proposed.DocType = DocTypePermissionPolicy
+	proposed.Version = current.Version + 1
+	if proposed.ApprovalPercent == 0 {
+		proposed.ApprovalPercent = current.ApprovalPercent
+	}
+	if err := proposed.Validate(); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := &PolicyProposal{
+		DocType:     DocTypePolicyProposal,
+		ProposalID:  GenerateID(ctx, "POL", identity.MSPID),
+		BaseVersion: current.Version,
+		Policy:      proposed,
+		Reason:      reason,
+		ProposedBy:  identity.ID,
+		ProposerOrg: identity.MSPID,
+		ProposedAt:  timestamp,
+		Approvals: []PolicyApproval{{
+			Org:        identity.MSPID,
+			ApprovedBy: identity.ID,
+			ApprovedAt: timestamp,
+		}},
+		Status: ProposalPending,
+	}
+
+	if _, err := adoptIfApproved(ctx, proposal, current, timestamp); err != nil {
+		return nil, err
+	}
+	if err := putPolicyProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	emitPolicyEvent(ctx, proposal, identity, timestamp)
+
+	return proposal, nil
+}
+
+// ProposePolicyChange proposes a new permission policy
+// policyJSON holds "rolePermissions", "organizations" (the full registry, keyed
+// by MSP ID) and optionally "approvalRules" and "approvalPercent" (default:
+// unchanged). The proposing organization's approval is recorded with the
+// proposal.
+func (s *EvidenceContract) ProposePolicyChange(
+	ctx contractapi.TransactionContextInterface,
+	policyJSON string,
+	reason string,
+) (*PolicyProposal, error) {
+	identity, err := requireGovernanceAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var input struct {
+		RolePermissions map[Role][]Permission         `json:"rolePermissions"`
+		Organizations   map[string]OrganizationRecord `json:"organizations"`
+		ApprovalRules   map[ActionType]ApprovalRule   `json:"approvalRules"`
+		ApprovalPercent int                           `json:"approvalPercent"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(policyJSON)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %v", err)
+	}
+
+	current, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ApprovalRules == nil {
+		input.ApprovalRules = current.ApprovalRules
+	}
+
+	return proposePolicy(ctx, identity, current, PermissionPolicy{
+		RolePermissions: input.RolePermissions,
+		Organizations:   input.Organizations,
+		ApprovalRules:   input.ApprovalRules,
+		ApprovalPercent: input.ApprovalPercent,
+	}, reason)
+}
+
+// ProposeOrganizationChange proposes registering a new member organization or replacing a registry entry
+// organizationJSON is an OrganizationRecord. The rest of the policy in force is
+// carried over unchanged, and the proposal is approved like any policy change;
+// a new member takes part in governance once the change is adopted.
+func (s *EvidenceContract) ProposeOrganizationChange(
+	ctx contractapi.TransactionContextInterface,
+	organizationJSON string,
+	reason string,
+) (*PolicyProposal, error) {
+	identity, err := requireGovernanceAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var org OrganizationRecord
+	decoder := json.NewDecoder(bytes.NewReader([]byte(organizationJSON)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&org); err != nil {
+		return nil, fmt.Errorf("failed to parse organization: %v", err)
+	}
+	if strings.TrimSpace(org.MSPID) == "" {
+		return nil, fmt.Errorf("organization MSP ID is required")
+	}
+
+	current, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposed := PermissionPolicy{
+		RolePermissions: current.RolePermissions,
+		Organizations:   map[string]OrganizationRecord{},
+		ApprovalRules:   current.ApprovalRules,
+		ApprovalPercent: current.ApprovalPercent,
+	}
+	for msp, existing := range current.Organizations {
+		proposed.Organizations[msp] = existing
+	}
+	proposed.Organizations[org.MSPID] = org
+
+	return proposePolicy(ctx, identity, current, proposed, reason)
+}
+
+// ApprovePolicyChange records the caller's organization's approval of a pending proposal
+// The policy is adopted as soon as enough member organizations have approved.
+func (s *EvidenceContract) ApprovePolicyChange(
+	ctx contractapi.TransactionContextInterface,
+	proposalID string,
+) (*PolicyProposal, error) {
+	identity, err := requireGovernanceAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal, err := getPolicyProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != ProposalPending {
+		return nil, fmt.Errorf("policy proposal %s is not pending, current status: %s", proposalID, proposal.Status)
+	}
+
+	current, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.BaseVersion != current.Version {
+		return nil, fmt.Errorf("policy proposal %s was made against version %d, the policy in force is version %d", proposalID, proposal.BaseVersion, current.Version)
+	}
+	for _, a := range proposal.Approvals {
+		if a.Org == identity.MSPID {
+			return nil, fmt.Errorf("%s has already approved policy proposal %s", identity.MSPID, proposalID)
+		}
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal.Approvals = append(proposal.Approvals, PolicyApproval{
+		Org:        identity.MSPID,
+		ApprovedBy: identity.ID,
+		ApprovedAt: timestamp,
+	})
+
+	if _, err := adoptIfApproved(ctx, proposal, current, timestamp); err != nil {
+		return nil, err
+	}
+	if err := putPolicyProposal(ctx, proposal); err != nil {
+		return nil, err
+	}
+
+	emitPolicyEvent(ctx, proposal, identity, timestamp)
+
+	return proposal, nil
+}
+
+// WithdrawPolicyProposal withdraws a pending proposal; only the proposing organization may withdraw it
+func (s *EvidenceContract) WithdrawPolicyProposal(
+	ctx contractapi.TransactionContextInterface,
+	proposalID string,
+) error {
+	identity, err := requireGovernanceAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := getPolicyProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != ProposalPending {
+		return fmt.Errorf("policy proposal %s is not pending, current status: %s", proposalID, proposal.Status)
+	}
+	if proposal.ProposerOrg != identity.MSPID {
+		return fmt.Errorf("only %s can withdraw policy proposal %s", proposal.ProposerOrg, proposalID)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	proposal.Status = ProposalWithdrawn
+	proposal.ResolvedAt = timestamp
+	if err := putPolicyProposal(ctx, proposal); err != nil {
+		return err
+	}
+
+	emitPolicyEvent(ctx, proposal, identity, timestamp)
+
+	return nil
+}
+
+// GetPolicyProposal returns a policy proposal
+func (s *EvidenceContract) GetPolicyProposal(
+	ctx contractapi.TransactionContextInterface,
+	proposalID string,
+) (*PolicyProposal, error) {
+	_, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPolicyProposal(ctx, proposalID)
+}
+
+// GetPermissionPolicy returns the permission policy in force
+func (s *EvidenceContract) GetPermissionPolicy(
+	ctx contractapi.TransactionContextInterface,
+) (*PermissionPolicy, error) {
+	_, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPermissionPolicy(ctx)
+}
+
+// GetOrganization returns a member organization's registry entry
+func (s *EvidenceContract) GetOrganization(
+	ctx contractapi.TransactionContextInterface,
+	mspID string,
+) (*OrganizationRecord, error) {
+	_, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, ok := policy.Organizations[mspID]
+	if !ok {
+		return nil, fmt.Errorf("organization %s is not registered", mspID)
+	}
+
+	return &org, nil
+}
+
+// ListOrganizations returns the organization registry, sorted by MSP ID
+func (s *EvidenceContract) ListOrganizations(
+	ctx contractapi.TransactionContextInterface,
+) ([]OrganizationRecord, error) {
+	_, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := getPermissionPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgs := make([]OrganizationRecord, 0, len(policy.Organizations))
+	for _, msp := range policy.Members() {
+		orgs = append(orgs, policy.Organizations[msp])
+	}
+
+	return orgs, nil
+}
+
+// GetPolicyHistory returns every adopted permission policy version, oldest first
+// The built-in default (version 0) is not stored and is not included.
+func (s *EvidenceContract) GetPolicyHistory(
+	ctx contractapi.TransactionContextInterface,
+) ([]PermissionPolicy, error) {
+	_, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(policyVersionObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	versions := []PermissionPolicy{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var policy PermissionPolicy
+		if err := json.Unmarshal(queryResult.Value, &policy); err != nil {
+			return nil, err
+		}
+		versions = append(versions, policy)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,328 @@
+// Copyright Evidentia Chain-of-Custody System
+// Composite-key secondary indexes
+//
+// Design Decision: The list queries used CouchDB rich queries, which are not
+// re-executed at validation time (no phantom-read protection) and do not run on
+// LevelDB peers. Secondary indexes are now kept as composite keys next to the
+// records they point to and read with GetStateByPartialCompositeKey, which works
+// on any state database and is re-checked at commit. Custody events need no
+// separate index: they are already stored under EVENT~<evidenceID>~<sequence>~<txID>,
+// and RebuildIndexes moves events left under the older plain keys onto that
+// chain. The paginated queries and SearchEvidence remain CouchDB-only.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Composite key object types for secondary indexes
+const (
+	idxCaseEvidence        = "IDX_CASE_EVIDENCE"        // caseID -> evidenceID
+	idxStatusEvidence      = "IDX_STATUS_EVIDENCE"      // status -> evidenceID
+	idxEvidenceAnalysis    = "IDX_EVIDENCE_ANALYSIS"    // evidenceID -> analysisID
+	idxEvidenceReview      = "IDX_EVIDENCE_REVIEW"      // evidenceID -> reviewID
+	idxEvidenceDerived     = "IDX_EVIDENCE_DERIVED"     // parent evidenceID -> derived evidenceID
+	idxEvidenceAttestation = "IDX_EVIDENCE_ATTESTATION" // evidenceID -> attestationID
+	idxActionStatus        = "IDX_ACTION_STATUS"        // action status -> actionID
+	idxEvidenceAction      = "IDX_EVIDENCE_ACTION"      // evidenceID -> actionID
+	idxEvidenceAccess      = "IDX_EVIDENCE_ACCESS"      // evidenceID -> access requestID
+	idxRequesterAccess     = "IDX_REQUESTER_ACCESS"     // requester ID -> access requestID
+)
+
+// indexValue is stored under every index key; the key itself carries the data
+var indexValue = []byte{0x00}
+
+// putIndexEntry writes an index key
+func putIndexEntry(ctx contractapi.TransactionContextInterface, objectType string, attributes ...string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", objectType, err)
+	}
+	return ctx.GetStub().PutState(key, indexValue)
+}
+
+// deleteIndexEntry removes an index key
+func deleteIndexEntry(ctx contractapi.TransactionContextInterface, objectType string, attributes ...string) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to create %s index key: %v", objectType, err)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+// getIndexedIDs returns the last attribute of every index key under a partial key
+func getIndexedIDs(ctx contractapi.TransactionContextInterface, objectType string, attributes ...string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	var ids []string
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		_, keyParts, err := ctx.GetStub().SplitCompositeKey(queryResult.Key)
+		if err != nil {
+			return nil, err
+		}
+		if len(keyParts) == 0 {
+			continue
+		}
+		ids = append(ids, keyParts[len(keyParts)-1])
+	}
+
+	return ids, nil
+}
+
+// putEvidence stores an evidence record and keeps its case and status index keys in step
+// The committed record is read to find stale index keys, since a transaction
+// cannot see its own pending writes; store each evidence record at most once per transaction.
+func putEvidence(ctx contractapi.TransactionContextInterface, evidence *Evidence) error {
+	previousJSON, err := ctx.GetStub().GetState(evidence.ID)
+	if err != nil {
+		return fmt.Errorf("failed to read evidence: %v", err)
+	}
+
+	var previous *Evidence
+	if previousJSON != nil {
+		previous = &Evidence{}
+		if err := json.Unmarshal(previousJSON, previous); err != nil {
+			return err
+		}
+	}
+	// Disposal is final; this guards every path that updates evidence
+	if previous != nil {
+		if err := RequireNotDisposed(previous); err != nil {
+			return err
+		}
+	}
+
+	evidenceJSON, err := evidence.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(evidence.ID, evidenceJSON); err != nil {
+		return fmt.Errorf("failed to store evidence: %v", err)
+	}
+
+	if previous == nil || previous.CaseID != evidence.CaseID {
+		if previous != nil {
+			if err := deleteIndexEntry(ctx, idxCaseEvidence, previous.CaseID, evidence.ID); err != nil {
+				return err
+			}
+		}
+		if err := putIndexEntry(ctx, idxCaseEvidence, evidence.CaseID, evidence.ID); err != nil {
+			return err
+		}
+	}
+
+	if previous == nil || previous.Status != evidence.Status {
+		if previous != nil {
+			if err := deleteIndexEntry(ctx, idxStatusEvidence, string(previous.Status), evidence.ID); err != nil {
+				return err
+			}
+		}
+		if err := putIndexEntry(ctx, idxStatusEvidence, string(evidence.Status), evidence.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getIndexedEvidence loads the evidence records referenced by an index, redacted for the caller
+// Items the caller's attributes do not clear (see abac.go) are left out.
+func getIndexedEvidence(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	objectType string,
+	attributes ...string,
+) ([]Evidence, error) {
+	ids, err := getIndexedIDs(ctx, objectType, attributes...)
+	if err != nil {
+		return nil, err
+	}
+
+	evidenceList := []Evidence{}
+	cases := map[string]*Case{}
+	for _, id := range ids {
+		evidence, err := getEvidenceState(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if checkEvidenceAttributes(ctx, identity, evidence, cases) != nil {
+			continue
+		}
+		if err := applyContentAccess(ctx, identity, evidence); err != nil {
+			return nil, err
+		}
+		evidenceList = append(evidenceList, *evidence)
+	}
+
+	return evidenceList, nil
+}
+
+// getIndexedAnalysisRecords loads the analysis records of an evidence item
+func getIndexedAnalysisRecords(ctx contractapi.TransactionContextInterface, evidenceID string) ([]AnalysisRecord, error) {
+	ids, err := getIndexedIDs(ctx, idxEvidenceAnalysis, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []AnalysisRecord{}
+	for _, id := range ids {
+		recordJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, err
+		}
+		if recordJSON == nil {
+			return nil, fmt.Errorf("indexed analysis %s not found", id)
+		}
+
+		var record AnalysisRecord
+		if err := json.Unmarshal(recordJSON, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// getIndexedJudicialReviews loads the judicial reviews of an evidence item
+func getIndexedJudicialReviews(ctx contractapi.TransactionContextInterface, evidenceID string) ([]JudicialReview, error) {
+	ids, err := getIndexedIDs(ctx, idxEvidenceReview, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := []JudicialReview{}
+	for _, id := range ids {
+		reviewJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, err
+		}
+		if reviewJSON == nil {
+			return nil, fmt.Errorf("indexed review %s not found", id)
+		}
+
+		var review JudicialReview
+		if err := json.Unmarshal(reviewJSON, &review); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+// RebuildIndexes writes the secondary index keys for every evidence record,
+// analysis record, judicial review, integrity attestation, pending action and
+// access request in world state, and migrates custody events stored under the
+// pre-sequencing keys
+// Records created before the indexes existed are invisible to the list queries,
+// and legacy custody events to the history and chain queries, until this has
+// been run once after upgrading. Returns the number of records indexed or migrated.
+func (s *EvidenceContract) RebuildIndexes(
+	ctx contractapi.TransactionContextInterface,
+) (int, error) {
+	_, err := RequirePermission(ctx, PermManageLedger)
+	if err != nil {
+		return 0, err
+	}
+
+	// A range over simple keys never returns composite keys
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	indexed := 0
+	legacyEvents := map[string][]storedEvent{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var doc struct {
+			DocType     string         `json:"docType"`
+			CaseID      string         `json:"caseId"`
+			Status      EvidenceStatus `json:"status"`
+			EvidenceID  string         `json:"evidenceId"`
+			ParentIDs   []string       `json:"parentEvidenceIds"`
+			RequesterID string         `json:"requesterId"`
+		}
+		if err := json.Unmarshal(queryResult.Value, &doc); err != nil {
+			continue
+		}
+
+		switch doc.DocType {
+		case DocTypeEvidence:
+			if err := putIndexEntry(ctx, idxCaseEvidence, doc.CaseID, queryResult.Key); err != nil {
+				return 0, err
+			}
+			if err := putIndexEntry(ctx, idxStatusEvidence, string(doc.Status), queryResult.Key); err != nil {
+				return 0, err
+			}
+			for _, parentID := range doc.ParentIDs {
+				if err := putIndexEntry(ctx, idxEvidenceDerived, parentID, queryResult.Key); err != nil {
+					return 0, err
+				}
+			}
+		case DocTypeAnalysisRecord:
+			if err := putIndexEntry(ctx, idxEvidenceAnalysis, doc.EvidenceID, queryResult.Key); err != nil {
+				return 0, err
+			}
+		case DocTypeJudicialReview:
+			if err := putIndexEntry(ctx, idxEvidenceReview, doc.EvidenceID, queryResult.Key); err != nil {
+				return 0, err
+			}
+		case DocTypeIntegrityAttestation:
+			if err := putIndexEntry(ctx, idxEvidenceAttestation, doc.EvidenceID, queryResult.Key); err != nil {
+				return 0, err
+			}
+		case DocTypePendingAction:
+			if err := putIndexEntry(ctx, idxActionStatus, string(doc.Status), queryResult.Key); err != nil {
+				return 0, err
+			}
+			if err := putIndexEntry(ctx, idxEvidenceAction, doc.EvidenceID, queryResult.Key); err != nil {
+				return 0, err
+			}
+		case DocTypeAccessRequest:
+			if err := putIndexEntry(ctx, idxEvidenceAccess, doc.EvidenceID, queryResult.Key); err != nil {
+				return 0, err
+			}
+			if err := putIndexEntry(ctx, idxRequesterAccess, doc.RequesterID, queryResult.Key); err != nil {
+				return 0, err
+			}
+		case DocTypeCustodyEvent:
+			// Sequenced events have composite keys, so any found here are legacy
+			var event CustodyEvent
+			if err := json.Unmarshal(queryResult.Value, &event); err != nil {
+				return 0, fmt.Errorf("failed to parse custody event %s: %v", queryResult.Key, err)
+			}
+			legacyEvents[doc.EvidenceID] = append(legacyEvents[doc.EvidenceID], storedEvent{Key: queryResult.Key, Event: event})
+			continue
+		default:
+			continue
+		}
+		indexed++
+	}
+
+	migrated, err := migrateLegacyEvents(ctx, legacyEvents)
+	if err != nil {
+		return 0, err
+	}
+
+	return indexed + migrated, nil
+}
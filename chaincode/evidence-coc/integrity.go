@@ -0,0 +1,197 @@
+// Copyright Evidentia Chain-of-Custody System
+// Integrity failure escalation and quarantine
+//
+// Design Decision: A failed integrity check used to set IntegrityVerified=false
+// and nothing else, so altered evidence could still be transferred, analysed and
+// put before a court. A failed check now moves the evidence to COMPROMISED and
+// opens an IntegrityIncident recording the status it held. Transfers, analysis,
+// derivation and judicial submission refuse compromised evidence. Only a
+// supervisor can close the incident, with a written resolution, either restoring
+// the quarantined status after the digests verify again or retiring the item to
+// ARCHIVED.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// flagIntegrityFailure quarantines evidence after a failed check and raises an IntegrityFailure event
+// The evidence is updated in memory; the caller stores it and records the
+// INTEGRITY_FAILURE custody event. Evidence that is already quarantined keeps
+// its original incident, and disposed evidence cannot be quarantined.
+func flagIntegrityFailure(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	evidence *Evidence,
+	check string,
+	timestamp int64,
+) {
+	evidence.IntegrityVerified = false
+
+	if evidence.Status != StatusCompromised && ValidateStatusTransition(ctx, nil, evidence.Status, StatusCompromised) == nil {
+		evidence.Quarantine = &IntegrityIncident{
+			IncidentID:     GenerateID(ctx, "INC", evidence.ID),
+			Check:          check,
+			PreviousStatus: evidence.Status,
+			DetectedBy:     identity.ID,
+			DetectedOrg:    identity.MSPID,
+			DetectedAt:     timestamp,
+			TxID:           ctx.GetStub().GetTxID(),
+		}
+		evidence.Status = StatusCompromised
+	}
+
+	incidentID := ""
+	if evidence.Quarantine != nil {
+		incidentID = evidence.Quarantine.IncidentID
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       "INTEGRITY_FAILURE",
+		"evidenceId": evidence.ID,
+		"caseId":     evidence.CaseID,
+		"incidentId": incidentID,
+		"check":      check,
+		"status":     evidence.Status,
+		"custodian":  evidence.CurrentCustodian,
+		"custodyOrg": evidence.CurrentOrg,
+		"detectedBy": identity.ID,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent("IntegrityFailure", eventPayload)
+}
+
+// ResolveIntegrityFailure closes the integrity incident of quarantined evidence
+// Parameters:
+//   - resolution: Findings of the investigation (required)
+//   - verifiedHash: Digest string re-checked against the registered digests;
+//     required to return the evidence to service, optional when archiving
+//   - targetStatus: Empty or the quarantined status to restore it, or ARCHIVED to retire it
+//
+// The caller must be in the custodian organization unless they are an administrator.
+func (s *EvidenceContract) ResolveIntegrityFailure(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	resolution string,
+	verifiedHash string,
+	targetStatus string,
+) error {
+	identity, err := RequirePermission(ctx, PermResolveIntegrity)
+	if err != nil {
+		return err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return err
+	}
+	if evidence.Status != StatusCompromised || evidence.Quarantine == nil {
+		return fmt.Errorf("evidence %s is not quarantined, current status: %s", evidenceID, evidence.Status)
+	}
+	if identity.MSPID != evidence.CurrentOrg && identity.Role != RoleAdmin {
+		return fmt.Errorf("only %s can resolve the integrity incident on evidence %s", evidence.CurrentOrg, evidenceID)
+	}
+
+	resolution = strings.TrimSpace(resolution)
+	if resolution == "" {
+		return fmt.Errorf("a documented resolution is required")
+	}
+
+	incident := evidence.Quarantine
+	target := EvidenceStatus(targetStatus)
+	if target == "" {
+		target = incident.PreviousStatus
+	}
+	if target != incident.PreviousStatus && target != StatusArchived {
+		return fmt.Errorf("quarantined evidence can only return to %s or be retired to %s", incident.PreviousStatus, StatusArchived)
+	}
+	if err := ValidateStatusTransition(ctx, identity, StatusCompromised, target); err != nil {
+		return err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Returning evidence to service requires its digests to verify again
+	reverified := []HashAlgorithm{}
+	if verifiedHash != "" || target != StatusArchived {
+		if verifiedHash == "" {
+			return fmt.Errorf("a digest that verifies against the registered digests is required to restore evidence %s", evidenceID)
+		}
+		verified, digests, err := MatchDigests(evidence.RegisteredDigests(), verifiedHash)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			return fmt.Errorf("provided digest does not match the registered digests of evidence %s", evidenceID)
+		}
+		reverified = DigestAlgorithms(digests)
+
+		attestation := newAttestation(ctx, identity, evidenceID, VerifyUnspecified, "", digests, true, timestamp)
+		attestation.IncidentID = incident.IncidentID
+		if err := putAttestation(ctx, attestation); err != nil {
+			return err
+		}
+		evidence.IntegrityVerified = true
+		evidence.LastVerifiedAt = timestamp
+		evidence.LastAttestationID = attestation.AttestationID
+	}
+
+	evidence.Status = target
+	evidence.Quarantine = nil
+	evidence.UpdatedAt = timestamp
+
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"incidentId":           incident.IncidentID,
+		"check":                incident.Check,
+		"detectedBy":           incident.DetectedBy,
+		"detectedAt":           incident.DetectedAt,
+		"resolution":           resolution,
+		"newStatus":            target,
+		"reverifiedAlgorithms": reverified,
+	})
+	if err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventIntegrityResolved,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        resolution,
+		Details:       string(details),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return err
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       "INTEGRITY_RESOLVED",
+		"evidenceId": evidenceID,
+		"incidentId": incident.IncidentID,
+		"newStatus":  target,
+		"resolvedBy": identity.ID,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent("IntegrityResolved", eventPayload)
+
+	return nil
+}
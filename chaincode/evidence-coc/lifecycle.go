@@ -0,0 +1,332 @@
+// Copyright Evidentia Chain-of-Custody System
+// On-ledger evidence lifecycle configuration
+//
+// Design Decision: The allowed status transitions used to be a map literal in
+// ValidateStatusTransition, so any workflow change (a RETURNED_TO_OWNER status,
+// ARCHIVED -> IN_ANALYSIS on appeal) needed a chaincode upgrade approved by every
+// organization. The graph, with the roles allowed to perform each transition, is
+// now a versioned LifecycleConfig on the ledger that administrators replace with
+// UpdateLifecycleConfig. Every version is kept. Until the first update the
+// built-in default (version 0) applies, so existing ledgers need no migration.
+//
+// Statuses the chaincode assigns itself cannot be removed, and every status
+// except DISPOSED must be able to move to COMPROMISED, so a failed integrity
+// check can always quarantine evidence. Nothing may leave DISPOSED.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// lifecycleConfigKey holds the current lifecycle version; every version is
+// also kept under a lifecycleVersionObjectType composite key
+const (
+	lifecycleConfigKey         = "LIFECYCLE_CONFIG"
+	lifecycleVersionObjectType = "LIFECYCLE_CONFIG_VERSION"
+)
+
+// builtinStatuses are assigned by chaincode logic and must stay in every lifecycle
+var builtinStatuses = []EvidenceStatus{
+	StatusRegistered,
+	StatusInCustody,
+	StatusInAnalysis,
+	StatusAnalyzed,
+	StatusUnderReview,
+	StatusAdmitted,
+	StatusRejected,
+	StatusArchived,
+	StatusDisposed,
+	StatusCompromised,
+}
+
+// defaultLifecycleConfig returns the built-in lifecycle (version 0)
+func defaultLifecycleConfig() *LifecycleConfig {
+	graph := []struct {
+		from EvidenceStatus
+		to   []EvidenceStatus
+	}{
+		{StatusRegistered, []EvidenceStatus{StatusInCustody}},
+		{StatusInCustody, []EvidenceStatus{StatusInAnalysis, StatusInCustody, StatusUnderReview, StatusArchived}},
+		{StatusInAnalysis, []EvidenceStatus{StatusAnalyzed, StatusInCustody}},
+		{StatusAnalyzed, []EvidenceStatus{StatusUnderReview, StatusInCustody, StatusInAnalysis}},
+		{StatusUnderReview, []EvidenceStatus{StatusAdmitted, StatusRejected, StatusInAnalysis}},
+		{StatusAdmitted, []EvidenceStatus{StatusArchived}},
+		{StatusRejected, []EvidenceStatus{StatusArchived, StatusInAnalysis}},
+		{StatusArchived, []EvidenceStatus{StatusDisposed}},
+	}
+
+	config := &LifecycleConfig{
+		DocType:  DocTypeLifecycleConfig,
+		Version:  0,
+		Statuses: append([]EvidenceStatus{}, builtinStatuses...),
+	}
+	for _, node := range graph {
+		for _, to := range node.to {
+			config.Transitions = append(config.Transitions, StatusTransitionRule{From: node.from, To: to})
+		}
+		// Any live status can be quarantined, and only a supervisor can restore it
+		config.Transitions = append(config.Transitions,
+			StatusTransitionRule{From: node.from, To: StatusCompromised},
+			StatusTransitionRule{From: StatusCompromised, To: node.from, Roles: []Role{RoleSupervisor, RoleAdmin}},
+		)
+	}
+
+	return config
+}
+
+// getLifecycleConfig returns the current lifecycle, or the built-in default if none is stored
+func getLifecycleConfig(ctx contractapi.TransactionContextInterface) (*LifecycleConfig, error) {
+	configJSON, err := ctx.GetStub().GetState(lifecycleConfigKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lifecycle config: %v", err)
+	}
+	if configJSON == nil {
+		return defaultLifecycleConfig(), nil
+	}
+
+	var config LifecycleConfig
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// HasStatus reports whether the lifecycle defines a status
+func (c *LifecycleConfig) HasStatus(status EvidenceStatus) bool {
+	for _, s := range c.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// transition returns the rule for a transition, or nil if it is not allowed
+func (c *LifecycleConfig) transition(from, to EvidenceStatus) *StatusTransitionRule {
+	for i := range c.Transitions {
+		if c.Transitions[i].From == from && c.Transitions[i].To == to {
+			return &c.Transitions[i]
+		}
+	}
+	return nil
+}
+
+// Allows checks a transition is in the graph and, if identity is given, that its role may perform it
+func (c *LifecycleConfig) Allows(identity *ClientIdentity, from, to EvidenceStatus) error {
+	if !c.HasStatus(from) {
+		return fmt.Errorf("unknown current status: %s", from)
+	}
+
+	rule := c.transition(from, to)
+	if rule == nil {
+		return fmt.Errorf("invalid status transition from %s to %s", from, to)
+	}
+
+	if identity != nil && len(rule.Roles) > 0 {
+		for _, role := range rule.Roles {
+			if role == identity.Role {
+				return nil
+			}
+		}
+		return fmt.Errorf("role %s may not move evidence from %s to %s", identity.Role, from, to)
+	}
+
+	return nil
+}
+
+// Validate checks a lifecycle is well formed and keeps the statuses the chaincode relies on
+func (c *LifecycleConfig) Validate() error {
+	seen := map[EvidenceStatus]bool{}
+	for _, s := range c.Statuses {
+		if strings.TrimSpace(string(s)) == "" {
+			return fmt.Errorf("status names cannot be empty")
+		}
+		if seen[s] {
+			return fmt.Errorf("status %s is listed more than once", s)
+		}
+		seen[s] = true
+	}
+	for _, s := range builtinStatuses {
+		if !seen[s] {
+			return fmt.Errorf("status %s is used by the chaincode and cannot be removed", s)
+		}
+	}
+
+	rules := map[string]bool{}
+	for _, t := range c.Transitions {
+		if !seen[t.From] || !seen[t.To] {
+			return fmt.Errorf("transition %s -> %s uses an unknown status", t.From, t.To)
+		}
+		if t.From == StatusDisposed {
+			return fmt.Errorf("disposed evidence is read-only; no transition may leave %s", StatusDisposed)
+		}
+		key := string(t.From) + "->" + string(t.To)
+		if rules[key] {
+			return fmt.Errorf("transition %s -> %s is listed more than once", t.From, t.To)
+		}
+		rules[key] = true
+		for _, role := range t.Roles {
+			if _, ok := RolePermissions[role]; !ok {
+				return fmt.Errorf("transition %s -> %s names unknown role %s", t.From, t.To, role)
+			}
+		}
+	}
+
+	for _, s := range c.Statuses {
+		if s == StatusDisposed || s == StatusCompromised {
+			continue
+		}
+		if !rules[string(s)+"->"+string(StatusCompromised)] {
+			return fmt.Errorf("status %s must allow a transition to %s", s, StatusCompromised)
+		}
+	}
+
+	return nil
+}
+
+// UpdateLifecycleConfig stores a new version of the evidence lifecycle
+// configJSON holds "statuses" and "transitions" as in LifecycleConfig; the
+// version and audit fields are set here. A status cannot be removed while
+// evidence holds it.
+func (s *EvidenceContract) UpdateLifecycleConfig(
+	ctx contractapi.TransactionContextInterface,
+	configJSON string,
+	reason string,
+) (*LifecycleConfig, error) {
+	identity, err := RequirePermission(ctx, PermManageLedger)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Role != RoleAdmin {
+		return nil, fmt.Errorf("only administrators can change the evidence lifecycle")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason for the lifecycle change is required")
+	}
+
+	var input struct {
+		Statuses    []EvidenceStatus       `json:"statuses"`
+		Transitions []StatusTransitionRule `json:"transitions"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(configJSON)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("failed to parse lifecycle config: %v", err)
+	}
+
+	current, err := getLifecycleConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &LifecycleConfig{
+		DocType:     DocTypeLifecycleConfig,
+		Version:     current.Version + 1,
+		Statuses:    input.Statuses,
+		Transitions: input.Transitions,
+		Reason:      reason,
+		UpdatedBy:   identity.ID,
+		UpdatedOrg:  identity.MSPID,
+		UpdatedAt:   timestamp,
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, status := range current.Statuses {
+		if config.HasStatus(status) {
+			continue
+		}
+		ids, err := getIndexedIDs(ctx, idxStatusEvidence, string(status))
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			return nil, fmt.Errorf("status %s cannot be removed while %d evidence items hold it", status, len(ids))
+		}
+	}
+
+	stored, err := config.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(lifecycleConfigKey, stored); err != nil {
+		return nil, fmt.Errorf("failed to store lifecycle config: %v", err)
+	}
+	versionKey, err := ctx.GetStub().CreateCompositeKey(lifecycleVersionObjectType, []string{fmt.Sprintf("%010d", config.Version)})
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(versionKey, stored); err != nil {
+		return nil, fmt.Errorf("failed to store lifecycle config version: %v", err)
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":      "LIFECYCLE_CONFIG_UPDATED",
+		"version":   config.Version,
+		"reason":    reason,
+		"updatedBy": identity.ID,
+		"timestamp": timestamp,
+	})
+	ctx.GetStub().SetEvent("LifecycleConfigUpdated", eventPayload)
+
+	return config, nil
+}
+
+// GetLifecycleConfig returns the evidence lifecycle currently in force
+func (s *EvidenceContract) GetLifecycleConfig(
+	ctx contractapi.TransactionContextInterface,
+) (*LifecycleConfig, error) {
+	_, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	return getLifecycleConfig(ctx)
+}
+
+// GetLifecycleConfigHistory returns every stored lifecycle version, oldest first
+// The built-in default (version 0) is not stored and is not included.
+func (s *EvidenceContract) GetLifecycleConfigHistory(
+	ctx contractapi.TransactionContextInterface,
+) ([]LifecycleConfig, error) {
+	_, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(lifecycleVersionObjectType, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	versions := []LifecycleConfig{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var config LifecycleConfig
+		if err := json.Unmarshal(queryResult.Value, &config); err != nil {
+			return nil, err
+		}
+		versions = append(versions, config)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,144 @@
+// Copyright Evidentia Chain-of-Custody System
+// Tests for lifecycle configuration validation and transition checks
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLifecycleConfigValidate(t *testing.T) {
+	const returned EvidenceStatus = "RETURNED_TO_OWNER"
+
+	tests := []struct {
+		name    string
+		edit    func(c *LifecycleConfig)
+		wantErr string
+	}{
+		{
+			name: "built-in default",
+			edit: func(c *LifecycleConfig) {},
+		},
+		{
+			name: "added status with quarantine",
+			edit: func(c *LifecycleConfig) {
+				c.Statuses = append(c.Statuses, returned)
+				c.Transitions = append(c.Transitions,
+					StatusTransitionRule{From: StatusArchived, To: returned, Roles: []Role{RoleSupervisor}},
+					StatusTransitionRule{From: returned, To: StatusCompromised},
+				)
+			},
+		},
+		{
+			name: "empty status",
+			edit: func(c *LifecycleConfig) {
+				c.Statuses = append(c.Statuses, " ")
+			},
+			wantErr: "cannot be empty",
+		},
+		{
+			name: "duplicate status",
+			edit: func(c *LifecycleConfig) {
+				c.Statuses = append(c.Statuses, StatusArchived)
+			},
+			wantErr: "listed more than once",
+		},
+		{
+			name: "built-in status removed",
+			edit: func(c *LifecycleConfig) {
+				statuses := []EvidenceStatus{}
+				for _, s := range c.Statuses {
+					if s != StatusAnalyzed {
+						statuses = append(statuses, s)
+					}
+				}
+				c.Statuses = statuses
+			},
+			wantErr: "cannot be removed",
+		},
+		{
+			name: "transition to unknown status",
+			edit: func(c *LifecycleConfig) {
+				c.Transitions = append(c.Transitions, StatusTransitionRule{From: StatusArchived, To: returned})
+			},
+			wantErr: "unknown status",
+		},
+		{
+			name: "transition out of DISPOSED",
+			edit: func(c *LifecycleConfig) {
+				c.Transitions = append(c.Transitions, StatusTransitionRule{From: StatusDisposed, To: StatusArchived})
+			},
+			wantErr: "read-only",
+		},
+		{
+			name: "duplicate transition",
+			edit: func(c *LifecycleConfig) {
+				c.Transitions = append(c.Transitions, StatusTransitionRule{From: StatusRegistered, To: StatusInCustody})
+			},
+			wantErr: "listed more than once",
+		},
+		{
+			name: "unknown role",
+			edit: func(c *LifecycleConfig) {
+				c.Transitions[0].Roles = []Role{"JANITOR"}
+			},
+			wantErr: "unknown role",
+		},
+		{
+			name: "status that cannot be quarantined",
+			edit: func(c *LifecycleConfig) {
+				c.Statuses = append(c.Statuses, returned)
+				c.Transitions = append(c.Transitions, StatusTransitionRule{From: StatusArchived, To: returned})
+			},
+			wantErr: "must allow a transition to " + string(StatusCompromised),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultLifecycleConfig()
+			tt.edit(config)
+
+			err := config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLifecycleConfigAllows(t *testing.T) {
+	analyst := &ClientIdentity{ID: "analyst", Role: RoleAnalyst}
+	supervisor := &ClientIdentity{ID: "supervisor", Role: RoleSupervisor}
+
+	tests := []struct {
+		name     string
+		identity *ClientIdentity
+		from, to EvidenceStatus
+		wantErr  bool
+	}{
+		{"unrestricted transition", analyst, StatusRegistered, StatusInCustody, false},
+		{"transition not in the graph", analyst, StatusRegistered, StatusAdmitted, true},
+		{"unknown current status", analyst, "LOST", StatusInCustody, true},
+		{"quarantine from any live status", analyst, StatusAnalyzed, StatusCompromised, false},
+		{"restore needs a supervisor", analyst, StatusCompromised, StatusAnalyzed, true},
+		{"supervisor restores", supervisor, StatusCompromised, StatusAnalyzed, false},
+		{"graph only check ignores roles", nil, StatusCompromised, StatusAnalyzed, false},
+		{"nothing leaves DISPOSED", supervisor, StatusDisposed, StatusArchived, true},
+	}
+	config := defaultLifecycleConfig()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.Allows(tt.identity, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Allows(%s -> %s) error = %v, want error %v", tt.from, tt.to, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright Evidentia Chain-of-Custody System
+// Derived evidence and lineage
+//
+// Design Decision: Files extracted from a disk image, carved artifacts and memory
+// strings are evidence items in their own right. A derived item records its
+// parents and how it was produced, and a parent -> child index key is written
+// for each parent, so the lineage can be walked in both directions without rich
+// queries. Parents are never modified, which keeps their custody chains intact;
+// each parent instead gets a DERIVATION custody event.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// maxLineageNodes bounds the size of a lineage walk
+const maxLineageNodes = 1000
+
+// RegisterDerivedEvidence registers an evidence item derived from one or more parents
+// Parameters:
+//   - evidenceID: Unique identifier for the derived evidence
+//   - parentEvidenceIDsJSON: JSON array of parent evidence IDs (all in one case)
+//   - ipfsHash, evidenceHash, encryptionKeyID, metadataJSON: as for RegisterEvidence
+//   - derivationMethod: How the item was produced (e.g. "file extraction")
+//   - derivationTool, toolVersion: Tool used to produce it
+//
+// The caller must hold custody of every parent. The derived item is registered
+// like any other (see storeNewEvidence); a chunk manifest can be added with
+// RecordChunkManifest.
+func (s *EvidenceContract) RegisterDerivedEvidence(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	parentEvidenceIDsJSON string,
+	ipfsHash string,
+	evidenceHash string,
+	encryptionKeyID string,
+	metadataJSON string,
+	derivationMethod string,
+	derivationTool string,
+	toolVersion string,
+) error {
+	identity, err := RequirePermission(ctx, PermDeriveEvidence)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.EvidenceExists(ctx, evidenceID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("evidence %s already exists", evidenceID)
+	}
+
+	var requestedParents []string
+	if err := json.Unmarshal([]byte(parentEvidenceIDsJSON), &requestedParents); err != nil {
+		return fmt.Errorf("failed to parse parent evidence IDs: %v", err)
+	}
+	parentIDs := []string{}
+	for _, id := range requestedParents {
+		if !Contains(parentIDs, id) {
+			parentIDs = append(parentIDs, id)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return fmt.Errorf("at least one parent evidence ID is required")
+	}
+	if derivationMethod == "" {
+		return fmt.Errorf("derivation method is required")
+	}
+
+	var metadata EvidenceMetadata
+	if err := json.Unmarshal([]byte(metadataJSON), &metadata); err != nil {
+		return fmt.Errorf("failed to parse metadata: %v", err)
+	}
+
+	// Every parent must be in the caller's custody and in the same case; the
+	// derived item carries the highest parent classification
+	caseID := ""
+	classification := ClassUnclassified
+	for _, parentID := range parentIDs {
+		parent, err := getEvidenceForCaller(ctx, identity, parentID)
+		if err != nil {
+			return err
+		}
+		if parent.CurrentCustodian != identity.ID {
+			return fmt.Errorf("parent evidence %s is not in the custody of %s", parentID, identity.ID)
+		}
+		if parent.InTransit {
+			return fmt.Errorf("parent evidence %s is in transit under transfer %s", parentID, parent.PendingTransferID)
+		}
+		if err := RequireNotDisposed(parent); err != nil {
+			return err
+		}
+		if err := RequireNotCompromised(parent); err != nil {
+			return err
+		}
+		if classificationRank[labelOf(parent.Classification)] > classificationRank[classification] {
+			classification = labelOf(parent.Classification)
+		}
+		if caseID == "" {
+			caseID = parent.CaseID
+		} else if parent.CaseID != caseID {
+			return fmt.Errorf("parent evidence belongs to different cases: %s and %s", caseID, parent.CaseID)
+		}
+	}
+
+	// Custody of the parents authorizes the derivation; the case must still accept evidence
+	c, err := getCase(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if c != nil && !c.AcceptsEvidence() {
+		return fmt.Errorf("case %s is %s and does not accept new evidence", caseID, c.Status)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	descriptor := EvidenceDescriptor{
+		EvidenceID:      evidenceID,
+		CaseID:          caseID,
+		IPFSHash:        ipfsHash,
+		EvidenceHash:    evidenceHash,
+		EncryptionKeyID: encryptionKeyID,
+		Metadata:        metadata,
+		Classification:  classification,
+	}
+	lineage := evidenceLineage{
+		ParentIDs: parentIDs,
+		Derivation: &Derivation{
+			Method:      derivationMethod,
+			Tool:        derivationTool,
+			ToolVersion: toolVersion,
+			DerivedBy:   identity.ID,
+			DerivedOrg:  identity.MSPID,
+			DerivedAt:   timestamp,
+		},
+	}
+	evidence, err := storeNewEvidence(ctx, identity, &descriptor, &lineage, timestamp)
+	if err != nil {
+		return err
+	}
+
+	// Record the derivation in each parent's custody chain
+	parentDetails, err := json.Marshal(map[string]interface{}{
+		"derivedEvidenceId": evidenceID,
+		"method":            derivationMethod,
+		"evidenceHash":      evidence.EvidenceHash,
+	})
+	if err != nil {
+		return err
+	}
+	for _, parentID := range parentIDs {
+		parentEvent := CustodyEvent{
+			DocType:       DocTypeCustodyEvent,
+			EvidenceID:    parentID,
+			EventType:     EventDerivation,
+			FromEntity:    identity.ID,
+			FromOrg:       identity.MSPID,
+			Reason:        fmt.Sprintf("Derived evidence %s registered", evidenceID),
+			Details:       string(parentDetails),
+			Timestamp:     timestamp,
+			PerformedBy:   identity.ID,
+			PerformerOrg:  identity.MSPID,
+			PerformerRole: identity.Role,
+			TxID:          ctx.GetStub().GetTxID(),
+		}
+		if err := recordCustodyEvent(ctx, &parentEvent); err != nil {
+			return err
+		}
+	}
+
+	eventPayload, _ := json.Marshal(map[string]interface{}{
+		"type":       "EVIDENCE_DERIVED",
+		"evidenceId": evidenceID,
+		"parents":    parentIDs,
+		"caseId":     caseID,
+		"registrant": identity.ID,
+		"timestamp":  timestamp,
+	})
+	ctx.GetStub().SetEvent("EvidenceDerived", eventPayload)
+
+	return nil
+}
+
+// lineageNodeFor builds a lineage node from an evidence record
+func lineageNodeFor(
+	ctx contractapi.TransactionContextInterface,
+	evidence *Evidence,
+	relation string,
+	depth int,
+) (*LineageNode, error) {
+	children, err := getIndexedIDs(ctx, idxEvidenceDerived, evidence.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	parents := evidence.ParentEvidenceIDs
+	if parents == nil {
+		parents = []string{}
+	}
+	if children == nil {
+		children = []string{}
+	}
+
+	return &LineageNode{
+		EvidenceID:        evidence.ID,
+		CaseID:            evidence.CaseID,
+		EvidenceHash:      evidence.EvidenceHash,
+		Digests:           evidence.RegisteredDigests(),
+		Status:            evidence.Status,
+		Relation:          relation,
+		Depth:             depth,
+		ParentEvidenceIDs: parents,
+		ChildEvidenceIDs:  children,
+		Derivation:        evidence.Derivation,
+		RegisteredAt:      evidence.CreatedAt,
+	}, nil
+}
+
+// walkLineage visits every item reachable from the start node in one direction, breadth first
+// Items the caller fails the ABAC checks for keep their links, so the walk and
+// Origins stay complete, but their hashes and derivation are withheld.
+func walkLineage(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	start *LineageNode,
+	relation string,
+	visited map[string]bool,
+) ([]LineageNode, error) {
+	nodes := []LineageNode{}
+	queue := []*LineageNode{start}
+	cases := map[string]*Case{}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		next := current.ChildEvidenceIDs
+		if relation == LineageAncestor {
+			next = current.ParentEvidenceIDs
+		}
+
+		for _, id := range next {
+			if visited[id] {
+				continue
+			}
+			visited[id] = true
+			if len(visited) > maxLineageNodes {
+				return nil, fmt.Errorf("lineage of %s exceeds %d items", start.EvidenceID, maxLineageNodes)
+			}
+
+			evidence, err := getEvidenceState(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			node, err := lineageNodeFor(ctx, evidence, relation, current.Depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkEvidenceAttributes(ctx, identity, evidence, cases); err != nil {
+				node.EvidenceHash = ""
+				node.Digests = []Digest{}
+				node.Derivation = nil
+				node.Redacted = true
+			}
+			nodes = append(nodes, *node)
+			queue = append(queue, node)
+		}
+	}
+
+	return nodes, nil
+}
+
+// GetEvidenceLineage returns every ancestor and descendant of an evidence item with its hash
+// Origins lists the original acquisitions the item ultimately derives from.
+func (s *EvidenceContract) GetEvidenceLineage(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*EvidenceLineage, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := lineageNodeFor(ctx, evidence, LineageSelf, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := walkLineage(ctx, identity, self, LineageAncestor, map[string]bool{evidenceID: true})
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := walkLineage(ctx, identity, self, LineageDescendant, map[string]bool{evidenceID: true})
+	if err != nil {
+		return nil, err
+	}
+
+	origins := []string{}
+	if len(self.ParentEvidenceIDs) == 0 {
+		origins = append(origins, evidenceID)
+	}
+	for _, node := range ancestors {
+		if len(node.ParentEvidenceIDs) == 0 {
+			origins = append(origins, node.EvidenceID)
+		}
+	}
+	sort.Strings(origins)
+
+	nodes := append([]LineageNode{*self}, ancestors...)
+	nodes = append(nodes, descendants...)
+
+	return &EvidenceLineage{
+		EvidenceID: evidenceID,
+		Origins:    origins,
+		Nodes:      nodes,
+	}, nil
+}
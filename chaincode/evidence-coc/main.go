@@ -12,7 +12,10 @@ import (
 )
 
 func main() {
-	evidenceChaincode, err := contractapi.NewChaincode(&EvidenceContract{})
+	contract := &EvidenceContract{}
+	contract.TransactionContextHandler = new(EvidenceTransactionContext)
+
+	evidenceChaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		log.Panicf("Error creating evidence-coc chaincode: %v", err)
 	}
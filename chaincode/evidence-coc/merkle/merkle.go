@@ -0,0 +1,224 @@
+// Copyright Evidentia Chain-of-Custody System
+// Package merkle builds and verifies Merkle trees over fixed-size evidence chunks
+//
+// Design Decision: Re-verifying a multi-terabyte image against one whole-file
+// digest means re-reading all of it. The image is instead split into fixed-size
+// chunks, and only the Merkle root, chunk size and chunk count are recorded on
+// the ledger. Any chunk can then be proven intact with its SHA-256 digest and a
+// proof of about log2(chunks) sibling hashes. The same package is used offline
+// to build trees and proofs and by the chaincode to check them.
+//
+// Hashing follows RFC 6962 domain separation, so a leaf can never be passed off
+// as an interior node:
+//
+//	leaf = SHA-256(0x00 || SHA-256(chunk))
+//	node = SHA-256(0x01 || left || right)
+//
+// A node without a sibling on its level is promoted unchanged to the next level.
+//
+// Typical offline use:
+//
+//	tree, err := merkle.BuildFromReader(image, merkle.DefaultChunkSize)
+//	root := tree.RootHex()
+//	proof, err := tree.Proof(42)
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultChunkSize is the chunk size used when none is chosen (4 MiB)
+const DefaultChunkSize = 4 << 20
+
+// Hash prefixes for domain separation
+const (
+	leafPrefix byte = 0x00
+	nodePrefix byte = 0x01
+)
+
+// Tree is a Merkle tree over chunk digests
+type Tree struct {
+	levels [][][]byte // levels[0] are leaf hashes, the last level holds the root
+}
+
+// Proof shows that one chunk belongs to a tree
+type Proof struct {
+	LeafIndex int64    `json:"leafIndex"` // Chunk index, from 0
+	LeafCount int64    `json:"leafCount"` // Number of chunks in the tree
+	Siblings  []string `json:"siblings"`  // Hex sibling hashes, leaf level first
+}
+
+// ChunkDigest returns the SHA-256 digest of a chunk
+func ChunkDigest(chunk []byte) []byte {
+	sum := sha256.Sum256(chunk)
+	return sum[:]
+}
+
+// LeafHash returns the tree leaf for a chunk digest
+func LeafHash(chunkDigest []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(chunkDigest)
+	return h.Sum(nil)
+}
+
+// NodeHash returns the parent of two nodes
+func NodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Build creates a tree from chunk digests, in chunk order
+func Build(chunkDigests [][]byte) (*Tree, error) {
+	if len(chunkDigests) == 0 {
+		return nil, errors.New("merkle: at least one chunk is required")
+	}
+
+	leaves := make([][]byte, len(chunkDigests))
+	for i, d := range chunkDigests {
+		if len(d) != sha256.Size {
+			return nil, fmt.Errorf("merkle: chunk %d digest is %d bytes, want %d", i, len(d), sha256.Size)
+		}
+		leaves[i] = LeafHash(d)
+	}
+
+	levels := [][][]byte{leaves}
+	for current := leaves; len(current) > 1; {
+		next := make([][]byte, 0, (len(current)+1)/2)
+		for i := 0; i < len(current); i += 2 {
+			if i+1 == len(current) {
+				next = append(next, current[i])
+				continue
+			}
+			next = append(next, NodeHash(current[i], current[i+1]))
+		}
+		levels = append(levels, next)
+		current = next
+	}
+
+	return &Tree{levels: levels}, nil
+}
+
+// BuildFromReader splits a stream into chunkSize chunks and builds their tree
+// Only the chunk digests are kept in memory. The last chunk may be short.
+func BuildFromReader(r io.Reader, chunkSize int) (*Tree, error) {
+	if chunkSize <= 0 {
+		return nil, errors.New("merkle: chunk size must be positive")
+	}
+
+	var digests [][]byte
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			digests = append(digests, ChunkDigest(buf[:n]))
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return Build(digests)
+}
+
+// Root returns the root hash
+func (t *Tree) Root() []byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// RootHex returns the root hash as lowercase hex
+func (t *Tree) RootHex() string {
+	return hex.EncodeToString(t.Root())
+}
+
+// LeafCount returns the number of chunks in the tree
+func (t *Tree) LeafCount() int64 {
+	return int64(len(t.levels[0]))
+}
+
+// Proof returns the inclusion proof for a chunk
+func (t *Tree) Proof(leafIndex int64) (*Proof, error) {
+	if leafIndex < 0 || leafIndex >= t.LeafCount() {
+		return nil, fmt.Errorf("merkle: chunk %d out of range [0, %d)", leafIndex, t.LeafCount())
+	}
+
+	proof := &Proof{
+		LeafIndex: leafIndex,
+		LeafCount: t.LeafCount(),
+		Siblings:  []string{},
+	}
+
+	idx := leafIndex
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := idx ^ 1
+		if sibling < int64(len(level)) {
+			proof.Siblings = append(proof.Siblings, hex.EncodeToString(level[sibling]))
+		}
+		idx /= 2
+	}
+
+	return proof, nil
+}
+
+// RootFromProof recomputes the root implied by a chunk digest and its proof
+// Sibling positions are derived from LeafIndex and LeafCount, so a proof only
+// verifies for the chunk position it was built for.
+func RootFromProof(chunkDigest []byte, proof *Proof) ([]byte, error) {
+	if proof.LeafCount <= 0 || proof.LeafIndex < 0 || proof.LeafIndex >= proof.LeafCount {
+		return nil, fmt.Errorf("merkle: chunk %d out of range [0, %d)", proof.LeafIndex, proof.LeafCount)
+	}
+	if len(chunkDigest) != sha256.Size {
+		return nil, fmt.Errorf("merkle: chunk digest is %d bytes, want %d", len(chunkDigest), sha256.Size)
+	}
+
+	node := LeafHash(chunkDigest)
+	idx, width := proof.LeafIndex, proof.LeafCount
+	used := 0
+	for width > 1 {
+		sibling := idx ^ 1
+		if sibling < width {
+			if used >= len(proof.Siblings) {
+				return nil, errors.New("merkle: proof is too short")
+			}
+			siblingHash, err := hex.DecodeString(proof.Siblings[used])
+			if err != nil || len(siblingHash) != sha256.Size {
+				return nil, fmt.Errorf("merkle: sibling %d is not a hex SHA-256 hash", used)
+			}
+			used++
+
+			if idx%2 == 0 {
+				node = NodeHash(node, siblingHash)
+			} else {
+				node = NodeHash(siblingHash, node)
+			}
+		}
+		idx /= 2
+		width = (width + 1) / 2
+	}
+
+	if used != len(proof.Siblings) {
+		return nil, errors.New("merkle: proof is too long")
+	}
+
+	return node, nil
+}
+
+// Verify reports whether a chunk digest and proof lead to the expected root
+func Verify(root []byte, chunkDigest []byte, proof *Proof) (bool, error) {
+	computed, err := RootFromProof(chunkDigest, proof)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(computed, root), nil
+}
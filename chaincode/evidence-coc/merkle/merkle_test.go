@@ -0,0 +1,280 @@
+// Copyright Evidentia Chain-of-Custody System
+// Tests for Merkle tree construction, proofs and verification
+
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// chunks returns n distinct chunks
+func chunks(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = []byte(fmt.Sprintf("chunk-%d", i))
+	}
+	return out
+}
+
+// digestsOf returns the chunk digests of chunks
+func digestsOf(data [][]byte) [][]byte {
+	out := make([][]byte, len(data))
+	for i, c := range data {
+		out[i] = ChunkDigest(c)
+	}
+	return out
+}
+
+func TestBuildRoot(t *testing.T) {
+	d := digestsOf(chunks(5))
+	l := make([][]byte, len(d))
+	for i := range d {
+		l[i] = LeafHash(d[i])
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  []byte
+	}{
+		{"single chunk", 1, l[0]},
+		{"two chunks", 2, NodeHash(l[0], l[1])},
+		{"three chunks promotes the last leaf", 3, NodeHash(NodeHash(l[0], l[1]), l[2])},
+		{"four chunks", 4, NodeHash(NodeHash(l[0], l[1]), NodeHash(l[2], l[3]))},
+		{"five chunks promotes across two levels", 5, NodeHash(NodeHash(NodeHash(l[0], l[1]), NodeHash(l[2], l[3])), l[4])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := Build(d[:tt.count])
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if !bytes.Equal(tree.Root(), tt.want) {
+				t.Errorf("root = %x, want %x", tree.Root(), tt.want)
+			}
+			if tree.RootHex() != hex.EncodeToString(tt.want) {
+				t.Errorf("RootHex = %s, want %x", tree.RootHex(), tt.want)
+			}
+			if tree.LeafCount() != int64(tt.count) {
+				t.Errorf("LeafCount = %d, want %d", tree.LeafCount(), tt.count)
+			}
+		})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		digests [][]byte
+	}{
+		{"no chunks", nil},
+		{"short digest", [][]byte{ChunkDigest([]byte("a")), []byte("short")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Build(tt.digests); err == nil {
+				t.Error("Build succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestBuildFromReader(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+
+	tests := []struct {
+		name      string
+		chunkSize int
+		wantErr   bool
+	}{
+		{"exact chunks", 5, false},
+		{"short last chunk", 6, false},
+		{"single chunk", 64, false},
+		{"zero chunk size", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := BuildFromReader(bytes.NewReader(data), tt.chunkSize)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("BuildFromReader succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildFromReader: %v", err)
+			}
+
+			var split [][]byte
+			for i := 0; i < len(data); i += tt.chunkSize {
+				end := i + tt.chunkSize
+				if end > len(data) {
+					end = len(data)
+				}
+				split = append(split, data[i:end])
+			}
+			want, err := Build(digestsOf(split))
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			if tree.RootHex() != want.RootHex() {
+				t.Errorf("root = %s, want %s", tree.RootHex(), want.RootHex())
+			}
+		})
+	}
+}
+
+func TestProofVerifiesEveryChunk(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 6, 7, 8, 13} {
+		t.Run(fmt.Sprintf("%d chunks", count), func(t *testing.T) {
+			d := digestsOf(chunks(count))
+			tree, err := Build(d)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			for i := int64(0); i < int64(count); i++ {
+				proof, err := tree.Proof(i)
+				if err != nil {
+					t.Fatalf("Proof(%d): %v", i, err)
+				}
+				ok, err := Verify(tree.Root(), d[i], proof)
+				if err != nil {
+					t.Fatalf("Verify(%d): %v", i, err)
+				}
+				if !ok {
+					t.Errorf("chunk %d does not verify", i)
+				}
+			}
+		})
+	}
+}
+
+func TestProofOutOfRange(t *testing.T) {
+	tree, err := Build(digestsOf(chunks(3)))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, idx := range []int64{-1, 3} {
+		if _, err := tree.Proof(idx); err == nil {
+			t.Errorf("Proof(%d) succeeded, want error", idx)
+		}
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	d := digestsOf(chunks(7))
+	tree, err := Build(d)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		leaf    int64
+		tamper  func(digest []byte, proof *Proof) []byte
+		wantErr bool
+	}{
+		{
+			name: "altered chunk",
+			leaf: 2,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				return ChunkDigest([]byte("chunk-2 altered"))
+			},
+		},
+		{
+			name: "digest of another chunk",
+			leaf: 4,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				return d[5]
+			},
+		},
+		{
+			name: "proof moved to another position",
+			leaf: 1,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.LeafIndex = 0
+				return digest
+			},
+		},
+		{
+			name: "altered sibling",
+			leaf: 6,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.Siblings[0] = strings.Repeat("0", 64)
+				return digest
+			},
+		},
+		{
+			name: "wrong leaf count",
+			leaf: 6,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.LeafCount = 8
+				return digest
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncated proof",
+			leaf: 0,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.Siblings = proof.Siblings[:len(proof.Siblings)-1]
+				return digest
+			},
+			wantErr: true,
+		},
+		{
+			name: "extended proof",
+			leaf: 0,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.Siblings = append(proof.Siblings, strings.Repeat("0", 64))
+				return digest
+			},
+			wantErr: true,
+		},
+		{
+			name: "sibling is not hex",
+			leaf: 5,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				proof.Siblings[0] = "not-hex"
+				return digest
+			},
+			wantErr: true,
+		},
+		{
+			name: "chunk digest too short",
+			leaf: 5,
+			tamper: func(digest []byte, proof *Proof) []byte {
+				return digest[:16]
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proof, err := tree.Proof(tt.leaf)
+			if err != nil {
+				t.Fatalf("Proof(%d): %v", tt.leaf, err)
+			}
+			digest := tt.tamper(d[tt.leaf], proof)
+
+			ok, err := Verify(tree.Root(), digest, proof)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Verify returned %v, want error", ok)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if ok {
+				t.Error("tampered proof verified")
+			}
+		})
+	}
+}
@@ -22,25 +22,45 @@ const (
 	StatusRejected    EvidenceStatus = "REJECTED"     // Rejected by court
 	StatusArchived    EvidenceStatus = "ARCHIVED"     // Case closed, archived
 	StatusDisposed    EvidenceStatus = "DISPOSED"     // Evidence disposed
+	StatusCompromised EvidenceStatus = "COMPROMISED"  // Failed integrity check, quarantined pending investigation
 )
 
 // EventType represents the type of custody event
 type EventType string
 
 const (
-	EventRegistration    EventType = "REGISTRATION"
-	EventTransfer        EventType = "TRANSFER"
-	EventAccessRequest   EventType = "ACCESS_REQUEST"
-	EventAccessGranted   EventType = "ACCESS_GRANTED"
-	EventAccessDenied    EventType = "ACCESS_DENIED"
-	EventAnalysisStart   EventType = "ANALYSIS_START"
-	EventAnalysisEnd     EventType = "ANALYSIS_END"
-	EventTagAdded        EventType = "TAG_ADDED"
-	EventStatusChange    EventType = "STATUS_CHANGE"
-	EventJudicialSubmit  EventType = "JUDICIAL_SUBMIT"
-	EventJudicialDecision EventType = "JUDICIAL_DECISION"
-	EventExport          EventType = "EXPORT"
-	EventVerification    EventType = "VERIFICATION"
+	EventRegistration          EventType = "REGISTRATION"
+	EventTransfer              EventType = "TRANSFER"
+	EventAccessRequest         EventType = "ACCESS_REQUEST"
+	EventAccessGranted         EventType = "ACCESS_GRANTED"
+	EventAccessDenied          EventType = "ACCESS_DENIED"
+	EventAnalysisStart         EventType = "ANALYSIS_START"
+	EventAnalysisEnd           EventType = "ANALYSIS_END"
+	EventTagAdded              EventType = "TAG_ADDED"
+	EventStatusChange          EventType = "STATUS_CHANGE"
+	EventJudicialSubmit        EventType = "JUDICIAL_SUBMIT"
+	EventJudicialDecision      EventType = "JUDICIAL_DECISION"
+	EventExport                EventType = "EXPORT"
+	EventVerification          EventType = "VERIFICATION"
+	EventSensitiveUpdate       EventType = "SENSITIVE_METADATA_UPDATE"
+	EventSensitiveAccess       EventType = "SENSITIVE_METADATA_ACCESS"
+	EventKeyEscrowed           EventType = "KEY_ESCROWED"
+	EventKeyReleased           EventType = "KEY_RELEASED"
+	EventAccessRevoked         EventType = "ACCESS_REVOKED"
+	EventAccessExtended        EventType = "ACCESS_EXTENDED"
+	EventAccessExpired         EventType = "ACCESS_EXPIRED"
+	EventTransferInitiated     EventType = "TRANSFER_INITIATED"
+	EventTransferRejected      EventType = "TRANSFER_REJECTED"
+	EventTransferCancelled     EventType = "TRANSFER_CANCELLED"
+	EventDerivation            EventType = "DERIVATION"
+	EventChunkManifest         EventType = "CHUNK_MANIFEST_RECORDED"
+	EventIntegrityFailure      EventType = "INTEGRITY_FAILURE"
+	EventIntegrityResolved     EventType = "INTEGRITY_RESOLVED"
+	EventClassificationChanged EventType = "CLASSIFICATION_CHANGED"
+	EventActionRequested       EventType = "ACTION_REQUESTED"
+	EventActionRejected        EventType = "ACTION_REJECTED"
+	EventActionExpired         EventType = "ACTION_EXPIRED"
+	EventDisposal              EventType = "DISPOSAL"
 )
 
 // Role represents user roles in the system
@@ -60,22 +80,68 @@ const (
 
 // Evidence represents a piece of digital evidence
 type Evidence struct {
-	DocType           string         `json:"docType"`           // For CouchDB queries
-	ID                string         `json:"id"`                // Unique evidence identifier
-	CaseID            string         `json:"caseId"`            // Associated case number
-	IPFSHash          string         `json:"ipfsHash"`          // IPFS CID of encrypted evidence
-	EvidenceHash      string         `json:"evidenceHash"`      // SHA-256 hash of original file
-	EncryptionKeyID   string         `json:"encryptionKeyId"`   // Reference to encryption key
-	Metadata          EvidenceMetadata `json:"metadata"`        // Evidence metadata
-	Status            EvidenceStatus `json:"status"`            // Current status
-	CurrentCustodian  string         `json:"currentCustodian"`  // Current custodian ID
-	CurrentOrg        string         `json:"currentOrg"`        // Current organization MSP ID
-	RegisteredBy      string         `json:"registeredBy"`      // Original registrant
-	CreatedAt         int64          `json:"createdAt"`         // Unix timestamp
-	UpdatedAt         int64          `json:"updatedAt"`         // Unix timestamp
-	Tags              []string       `json:"tags"`              // Classification tags
-	IntegrityVerified bool           `json:"integrityVerified"` // Last verification status
-	LastVerifiedAt    int64          `json:"lastVerifiedAt"`    // Last verification timestamp
+	DocType                 string             `json:"docType"`                           // For CouchDB queries
+	ID                      string             `json:"id"`                                // Unique evidence identifier
+	CaseID                  string             `json:"caseId"`                            // Associated case number
+	IPFSHash                string             `json:"ipfsHash"`                          // IPFS CID of encrypted evidence
+	EvidenceHash            string             `json:"evidenceHash"`                      // Primary digest of original file (SHA-256 on legacy records)
+	Digests                 []Digest           `json:"digests,omitempty"`                 // Algorithm-tagged digests of original file
+	PrimaryHashAlgorithm    HashAlgorithm      `json:"primaryHashAlgorithm,omitempty"`    // Algorithm of EvidenceHash
+	ChunkManifest           *ChunkManifest     `json:"chunkManifest,omitempty"`           // Merkle root over file chunks, if recorded
+	EncryptionKeyID         string             `json:"encryptionKeyId"`                   // Reference to encryption key
+	Metadata                EvidenceMetadata   `json:"metadata"`                          // Evidence metadata
+	Status                  EvidenceStatus     `json:"status"`                            // Current status
+	CurrentCustodian        string             `json:"currentCustodian"`                  // Current custodian ID
+	CurrentOrg              string             `json:"currentOrg"`                        // Current organization MSP ID
+	RegisteredBy            string             `json:"registeredBy"`                      // Original registrant
+	CreatedAt               int64              `json:"createdAt"`                         // Unix timestamp
+	UpdatedAt               int64              `json:"updatedAt"`                         // Unix timestamp
+	Tags                    []string           `json:"tags"`                              // Classification tags
+	IntegrityVerified       bool               `json:"integrityVerified"`                 // Last verification status
+	LastVerifiedAt          int64              `json:"lastVerifiedAt"`                    // Last verification timestamp
+	LastAttestationID       string             `json:"lastAttestationId,omitempty"`       // Attestation of the last verification
+	SensitiveMetadataHash   string             `json:"sensitiveMetadataHash,omitempty"`   // SHA-256 of private SensitiveMetadata
+	SensitiveClassification Classification     `json:"sensitiveClassification,omitempty"` // ClassificationLevel of the private SensitiveMetadata
+	EncryptionKeyHash       string             `json:"encryptionKeyHash,omitempty"`       // SHA-256 of the escrowed key record
+	InTransit               bool               `json:"inTransit"`                         // Handover initiated but not yet accepted
+	PendingTransferID       string             `json:"pendingTransferId,omitempty"`       // Transfer awaiting acceptance
+	ContentRedacted         bool               `json:"contentRedacted,omitempty"`         // IPFS/key fields withheld from caller (never stored)
+	ParentEvidenceIDs       []string           `json:"parentEvidenceIds,omitempty"`       // Evidence this item was derived from
+	Derivation              *Derivation        `json:"derivation,omitempty"`              // How this item was derived (nil for acquisitions)
+	Quarantine              *IntegrityIncident `json:"quarantine,omitempty"`              // Open integrity incident while COMPROMISED
+	Classification          Classification     `json:"classification,omitempty"`          // Security label (empty: UNCLASSIFIED)
+	DisposalCertificateID   string             `json:"disposalCertificateId,omitempty"`   // Certificate issued when the evidence was disposed
+}
+
+// EvidenceDescriptor describes one evidence item to register
+type EvidenceDescriptor struct {
+	EvidenceID      string           `json:"evidenceId"`      // Unique identifier for the evidence
+	CaseID          string           `json:"caseId"`          // Registered case (must be OPEN or ACTIVE)
+	IPFSHash        string           `json:"ipfsHash"`        // IPFS CID of encrypted evidence
+	EvidenceHash    string           `json:"evidenceHash"`    // Digest string, used when Digests is empty
+	Digests         []Digest         `json:"digests,omitempty"` // Algorithm-tagged digests (instead of EvidenceHash)
+	PrimaryHashAlgorithm HashAlgorithm `json:"primaryHashAlgorithm,omitempty"` // Defaults to the first strong digest
+	ChunkManifest   *ChunkManifest   `json:"chunkManifest,omitempty"` // Optional Merkle root over file chunks
+	EncryptionKeyID string           `json:"encryptionKeyId"` // Reference to encryption key
+	Metadata        EvidenceMetadata `json:"metadata"`        // Evidence metadata
+	Classification  Classification   `json:"classification,omitempty"` // Security label (default UNCLASSIFIED)
+}
+
+// BatchItemResult is the outcome of one item in a batch registration
+type BatchItemResult struct {
+	Index      int    `json:"index"`           // Position in the submitted array
+	EvidenceID string `json:"evidenceId"`      // Evidence identifier
+	Valid      bool   `json:"valid"`           // Item passed validation
+	Error      string `json:"error,omitempty"` // Why the item was rejected
+}
+
+// BatchRegistrationResult is the outcome of a batch registration
+// Registered is false, and nothing was written, if any item failed validation.
+type BatchRegistrationResult struct {
+	BatchID    string            `json:"batchId"`    // Identifier of the batch
+	Registered bool              `json:"registered"` // True if every item was registered
+	Count      int               `json:"count"`      // Number of items registered
+	Items      []BatchItemResult `json:"items"`      // Per-item results, in submitted order
 }
 
 // EvidenceMetadata contains descriptive information about evidence
@@ -92,11 +158,94 @@ type EvidenceMetadata struct {
 	ExaminerNotes   string `json:"examinerNotes"`   // Additional notes
 }
 
+// HashAlgorithm identifies a digest algorithm
+type HashAlgorithm string
+
+const (
+	HashMD5     HashAlgorithm = "MD5"      // Weak: legacy compatibility only
+	HashSHA1    HashAlgorithm = "SHA-1"    // Weak: legacy compatibility only
+	HashSHA256  HashAlgorithm = "SHA-256"
+	HashSHA512  HashAlgorithm = "SHA-512"
+	HashSHA3256 HashAlgorithm = "SHA3-256"
+	HashSHA3512 HashAlgorithm = "SHA3-512"
+)
+
+// Digest is a digest value tagged with its algorithm
+type Digest struct {
+	Algorithm HashAlgorithm `json:"algorithm"` // Digest algorithm
+	Value     string        `json:"value"`     // Lowercase hex digest
+}
+
+// IntegrityIncident describes the failed verification that quarantined an evidence item
+type IntegrityIncident struct {
+	IncidentID     string         `json:"incidentId"`     // Unique incident identifier
+	Check          string         `json:"check"`          // What failed (e.g. "SHA-256", "chunk 12")
+	PreviousStatus EvidenceStatus `json:"previousStatus"` // Status to restore once resolved
+	DetectedBy     string         `json:"detectedBy"`     // Verifier ID
+	DetectedOrg    string         `json:"detectedOrg"`    // Verifier organization
+	DetectedAt     int64          `json:"detectedAt"`     // Unix timestamp
+	TxID           string         `json:"txId"`           // Verification transaction
+}
+
+// VerificationMethod describes how a verifier obtained the digests they attest to
+type VerificationMethod string
+
+const (
+	VerifyFullRehash  VerificationMethod = "FULL_REHASH" // Original media or working copy re-hashed
+	VerifyIPFSFetch   VerificationMethod = "IPFS_FETCH"  // Stored copy fetched from IPFS, decrypted and hashed
+	VerifyChunkProof  VerificationMethod = "CHUNK_PROOF" // One chunk checked against the chunk manifest
+	VerifyUnspecified VerificationMethod = "UNSPECIFIED" // Recorded through VerifyIntegrity, method not stated
+)
+
+// IntegrityAttestation is the permanent record of one integrity verification
+type IntegrityAttestation struct {
+	DocType         string             `json:"docType"`
+	AttestationID   string             `json:"attestationId"`
+	EvidenceID      string             `json:"evidenceId"`
+	VerifierID      string             `json:"verifierId"`
+	VerifierOrg     string             `json:"verifierOrg"`
+	VerifierRole    Role               `json:"verifierRole"`
+	Method          VerificationMethod `json:"method"`
+	Tool            string             `json:"tool,omitempty"`        // Hashing tool and version
+	Algorithms      []HashAlgorithm    `json:"algorithms"`            // Algorithms compared
+	ProvidedDigests []Digest           `json:"providedDigests"`       // Full digests the verifier computed
+	ChunkIndex      *int64             `json:"chunkIndex,omitempty"`  // Set for CHUNK_PROOF attestations
+	Verified        bool               `json:"verified"`              // Outcome
+	IncidentID      string             `json:"incidentId,omitempty"`  // Integrity incident opened or resolved
+	Timestamp       int64              `json:"timestamp"`
+	TxID            string             `json:"txId"`
+}
+
+// ToJSON converts IntegrityAttestation to JSON bytes
+func (a *IntegrityAttestation) ToJSON() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// ChunkManifest records a Merkle tree over fixed-size chunks of the evidence file
+// See the merkle package for the tree construction.
+type ChunkManifest struct {
+	MerkleRoot string `json:"merkleRoot"` // Hex root hash of the chunk tree
+	ChunkSize  int64  `json:"chunkSize"`  // Chunk size in bytes (the last chunk may be short)
+	ChunkCount int64  `json:"chunkCount"` // Number of chunks
+	TotalSize  int64  `json:"totalSize"`  // File size in bytes
+}
+
+// Derivation describes how a derived evidence item was produced from its parents
+type Derivation struct {
+	Method      string `json:"method"`      // e.g. file extraction, carving, memory string extraction
+	Tool        string `json:"tool"`        // Tool used
+	ToolVersion string `json:"toolVersion"` // Version of tool
+	DerivedBy   string `json:"derivedBy"`   // Who registered the derived item
+	DerivedOrg  string `json:"derivedOrg"`  // Organization of the deriver
+	DerivedAt   int64  `json:"derivedAt"`   // Derivation timestamp
+}
+
 // CustodyEvent represents an event in the chain of custody
 type CustodyEvent struct {
 	DocType       string    `json:"docType"`       // For CouchDB queries
 	EventID       string    `json:"eventId"`       // Unique event identifier
 	EvidenceID    string    `json:"evidenceId"`    // Associated evidence ID
+	Sequence      uint64    `json:"sequence"`      // Per-evidence event sequence number
 	EventType     EventType `json:"eventType"`     // Type of event
 	FromEntity    string    `json:"fromEntity"`    // Source entity (if transfer)
 	FromOrg       string    `json:"fromOrg"`       // Source organization MSP ID
@@ -110,9 +259,361 @@ type CustodyEvent struct {
 	PerformerRole Role      `json:"performerRole"` // Role of performer
 	TxID          string    `json:"txId"`          // Fabric transaction ID
 	BlockNumber   uint64    `json:"blockNumber"`   // Block number (populated post-commit)
-	Verified      bool      `json:"verified"`      // Signature/integrity verified
+	PreviousHash  string    `json:"previousHash"`  // EventHash of the preceding event ("" for the first)
+	EventHash     string    `json:"eventHash"`     // SHA-256 of the event contents, including PreviousHash
+	Verified      bool      `json:"verified"`      // Hash chain verified up to this event (set on read)
+}
+
+// EventCounter tracks the custody event sequence of a single evidence item
+// Design Decision: Stored under its own key so that recording an event does not
+// require rewriting the evidence record (e.g. for access requests).
+type EventCounter struct {
+	DocType       string `json:"docType"`       // For CouchDB queries
+	EvidenceID    string `json:"evidenceId"`    // Associated evidence ID
+	LastSequence  uint64 `json:"lastSequence"`  // Sequence number of the latest event
+	LastTxID      string `json:"lastTxId"`      // Transaction that recorded the latest event
+	LastEventHash string `json:"lastEventHash"` // EventHash of the latest event (chain head)
+}
+
+// SequenceGapReport describes missing custody events for an evidence item
+type SequenceGapReport struct {
+	EvidenceID       string   `json:"evidenceId"`       // Evidence checked
+	LastSequence     uint64   `json:"lastSequence"`     // Latest sequence according to the counter
+	EventCount       int      `json:"eventCount"`       // Number of sequenced events found
+	MissingSequences []uint64 `json:"missingSequences"` // Sequence numbers with no stored event
+	Complete         bool     `json:"complete"`         // True if no sequence numbers are missing
+}
+
+// ChainVerificationResult is the outcome of recomputing an evidence item's custody hash chain
+type ChainVerificationResult struct {
+	EvidenceID       string `json:"evidenceId"`       // Evidence checked
+	EventCount       int    `json:"eventCount"`       // Number of sequenced events checked
+	HeadHash         string `json:"headHash"`         // EventHash of the last valid event
+	Valid            bool   `json:"valid"`            // True if every link verified
+	BrokenAtSequence uint64 `json:"brokenAtSequence"` // Sequence of the first broken link (0 if valid)
+	BrokenEventID    string `json:"brokenEventId"`    // EventID of the first broken link, if stored
+	Reason           string `json:"reason"`           // Why the link is broken
+}
+
+// TransferStatus represents the state of a two-phase custody handover
+type TransferStatus string
+
+const (
+	TransferPending   TransferStatus = "PENDING"   // Initiated, awaiting receiver
+	TransferAccepted  TransferStatus = "ACCEPTED"  // Receiver acknowledged receipt
+	TransferRejected  TransferStatus = "REJECTED"  // Receiver refused the handover
+	TransferCancelled TransferStatus = "CANCELLED" // Sender withdrew the handover
+)
+
+// CustodyTransfer represents a custody handover awaiting or completed by the receiver
+type CustodyTransfer struct {
+	DocType        string         `json:"docType"`        // For CouchDB queries
+	TransferID     string         `json:"transferId"`     // Unique transfer identifier
+	EvidenceID     string         `json:"evidenceId"`     // Evidence being handed over
+	FromEntity     string         `json:"fromEntity"`     // Custodian at initiation
+	FromOrg        string         `json:"fromOrg"`        // Custodian organization at initiation
+	ToEntity       string         `json:"toEntity"`       // Receiving identity
+	ToOrg          string         `json:"toOrg"`          // Receiving organization MSP ID
+	Reason         string         `json:"reason"`         // Reason for the handover
+	Status         TransferStatus `json:"status"`         // PENDING, ACCEPTED, REJECTED, CANCELLED
+	InitiatedBy    string         `json:"initiatedBy"`    // Who initiated the handover
+	InitiatedAt    int64          `json:"initiatedAt"`    // Initiation timestamp
+	ResolvedBy     string         `json:"resolvedBy"`     // Who accepted, rejected or cancelled
+	ResolvedAt     int64          `json:"resolvedAt"`     // Resolution timestamp
+	ResolutionNote string         `json:"resolutionNote"` // Receipt note or rejection/cancellation reason
+}
+
+// ToJSON converts CustodyTransfer to JSON bytes
+func (t *CustodyTransfer) ToJSON() ([]byte, error) {
+	return json.Marshal(t)
+}
+
+// StatusTransitionRule allows one evidence status transition
+type StatusTransitionRule struct {
+	From  EvidenceStatus `json:"from"`
+	To    EvidenceStatus `json:"to"`
+	Roles []Role         `json:"roles,omitempty"` // Roles that may perform it (empty: any role)
+}
+
+// LifecycleConfig is one version of the evidence lifecycle state machine
+// Design Decision: Stored on the ledger so the workflow can change without a
+// chaincode upgrade. Version 0 is the built-in default used until an
+// administrator stores a version.
+type LifecycleConfig struct {
+	DocType     string                 `json:"docType"`
+	Version     int                    `json:"version"`     // Incremented on every update
+	Statuses    []EvidenceStatus       `json:"statuses"`    // Every status evidence may hold
+	Transitions []StatusTransitionRule `json:"transitions"` // Allowed transitions
+	Reason      string                 `json:"reason,omitempty"`     // Why this version was adopted
+	UpdatedBy   string                 `json:"updatedBy,omitempty"`  // Administrator who stored it
+	UpdatedOrg  string                 `json:"updatedOrg,omitempty"` // Administrator's organization
+	UpdatedAt   int64                  `json:"updatedAt,omitempty"`  // Unix timestamp
+	TxID        string                 `json:"txId,omitempty"`       // Update transaction
+}
+
+// ToJSON converts LifecycleConfig to JSON bytes
+func (c *LifecycleConfig) ToJSON() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// OrgType classifies a member organization; chaincode logic keys off the type, not the MSP ID
+type OrgType string
+
+const (
+	OrgLawEnforcement OrgType = "LAW_ENFORCEMENT" // Collects and registers evidence
+	OrgLab            OrgType = "LAB"             // Forensic analysis (custody moves evidence into analysis)
+	OrgCourt          OrgType = "COURT"           // Receives judicial review submissions
+	OrgProsecution    OrgType = "PROSECUTION"     // Prosecutors
+	OrgDefense        OrgType = "DEFENSE"         // Defence counsel
+)
+
+// OrganizationRecord is a member organization in the organization registry
+type OrganizationRecord struct {
+	MSPID        string       `json:"mspId"`                  // Fabric MSP ID
+	Name         string       `json:"name"`                   // Display name
+	Type         OrgType      `json:"type"`                   // Organization type
+	DefaultRole  Role         `json:"defaultRole"`            // Role for certificates without a role attribute
+	Jurisdiction string       `json:"jurisdiction,omitempty"` // Courts: jurisdiction matched against Case.Jurisdiction
+	Permissions  []Permission `json:"permissions"`            // Organization-level permissions
+}
+
+// ActionType identifies a high-risk operation that can require multi-party approval
+type ActionType string
+
+const (
+	ActionDisposal           ActionType = "DISPOSAL"            // Status change to DISPOSED
+	ActionCrossOrgTransfer   ActionType = "CROSS_ORG_TRANSFER"  // Custody handover to another organization
+	ActionJudicialSubmission ActionType = "JUDICIAL_SUBMISSION" // Submission for judicial review
+)
+
+// ApprovalRule says how many approvals an operation needs and from whom
+type ApprovalRule struct {
+	Approvals  int       `json:"approvals"`          // Distinct approvers required (0: no approval needed)
+	Roles      []Role    `json:"roles,omitempty"`    // Approver roles accepted (empty: any role)
+	OrgTypes   []OrgType `json:"orgTypes,omitempty"` // Approver organization types accepted (empty: any member)
+	TTLSeconds int64     `json:"ttlSeconds"`         // Time allowed to collect the approvals
+}
+
+// PermissionPolicy is one version of the role permission matrix, the organization registry and the approval rules
+// Design Decision: Stored on the ledger and changed only by a proposal that a
+// majority of member organizations approve. The registered organizations are
+// the members that vote. Version 0 is the built-in default used until the
+// first proposal is adopted.
+type PermissionPolicy struct {
+	DocType         string                        `json:"docType"`
+	Version         int                           `json:"version"`              // Incremented on every adoption
+	RolePermissions map[Role][]Permission         `json:"rolePermissions"`      // Permissions of each role
+	Organizations   map[string]OrganizationRecord `json:"organizations"`        // Organization registry, by MSP ID
+	ApprovalRules   map[ActionType]ApprovalRule   `json:"approvalRules"`        // Multi-party approval of high-risk operations
+	ApprovalPercent int                           `json:"approvalPercent"`      // Share of member orgs needed to adopt a change (51-100)
+	ProposalID      string                        `json:"proposalId,omitempty"` // Proposal that adopted this version
+	AdoptedAt       int64                         `json:"adoptedAt,omitempty"`  // Unix timestamp
+	TxID            string                        `json:"txId,omitempty"`       // Adopting transaction
+}
+
+// ToJSON converts PermissionPolicy to JSON bytes
+func (p *PermissionPolicy) ToJSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// ProposalStatus represents the state of a governance proposal
+type ProposalStatus string
+
+const (
+	ProposalPending   ProposalStatus = "PENDING"   // Collecting approvals
+	ProposalAdopted   ProposalStatus = "ADOPTED"   // Approved and in force
+	ProposalWithdrawn ProposalStatus = "WITHDRAWN" // Withdrawn by the proposing organization
+)
+
+// PolicyApproval records one organization's approval of a proposal
+type PolicyApproval struct {
+	Org        string `json:"org"`        // Approving organization MSP ID
+	ApprovedBy string `json:"approvedBy"` // Approving administrator
+	ApprovedAt int64  `json:"approvedAt"` // Unix timestamp
+}
+
+// PolicyProposal is a proposed replacement for the permission policy
+type PolicyProposal struct {
+	DocType     string           `json:"docType"`
+	ProposalID  string           `json:"proposalId"`
+	BaseVersion int              `json:"baseVersion"` // Policy version the proposal replaces
+	Policy      PermissionPolicy `json:"policy"`      // Proposed matrix, registry and approval percentage
+	Reason      string           `json:"reason"`
+	ProposedBy  string           `json:"proposedBy"`
+	ProposerOrg string           `json:"proposerOrg"`
+	ProposedAt  int64            `json:"proposedAt"`
+	Approvals   []PolicyApproval `json:"approvals"`
+	Status      ProposalStatus   `json:"status"`
+	ResolvedAt  int64            `json:"resolvedAt,omitempty"`
+}
+
+// ToJSON converts PolicyProposal to JSON bytes
+func (p *PolicyProposal) ToJSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// ActionStatus represents the state of a pending high-risk operation
+type ActionStatus string
+
+const (
+	ActionPending  ActionStatus = "PENDING"  // Collecting approvals
+	ActionExecuted ActionStatus = "EXECUTED" // Approved and carried out
+	ActionRejected ActionStatus = "REJECTED" // Rejected by an approver or withdrawn by the requester
+	ActionExpired  ActionStatus = "EXPIRED"  // Not approved in time
+)
+
+// ActionApproval records one approver's sign-off on a pending action
+type ActionApproval struct {
+	ApproverID   string `json:"approverId"`        // Approving identity
+	ApproverOrg  string `json:"approverOrg"`       // Organization of the approver
+	ApproverRole Role   `json:"approverRole"`      // Role of the approver
+	Comment      string `json:"comment,omitempty"` // Optional remark
+	ApprovedAt   int64  `json:"approvedAt"`        // Unix timestamp
+}
+
+// PendingAction is a high-risk operation waiting for multi-party approval
+type PendingAction struct {
+	DocType            string            `json:"docType"`
+	ActionID           string            `json:"actionId"`
+	ActionType         ActionType        `json:"actionType"`
+	EvidenceID         string            `json:"evidenceId"`
+	Params             map[string]string `json:"params,omitempty"` // Operation arguments
+	Reason             string            `json:"reason"`           // Requester's justification
+	Rule               ApprovalRule      `json:"rule"`             // Rule in force when the action was requested
+	RequestedBy        string            `json:"requestedBy"`
+	RequesterOrg       string            `json:"requesterOrg"`
+	RequesterRole      Role              `json:"requesterRole"`
+	RequesterClearance Classification    `json:"requesterClearance,omitempty"` // Requester's "clearance" attribute
+	RequesterUnit      string            `json:"requesterUnit,omitempty"`      // Requester's "unit" attribute
+	RequesterCases     []string          `json:"requesterCases,omitempty"`     // Requester's "cases" attribute
+	RequestedAt        int64             `json:"requestedAt"`
+	ExpiresAt          int64             `json:"expiresAt"`
+	Approvals          []ActionApproval  `json:"approvals"`
+	Status             ActionStatus      `json:"status"`
+	ResolvedBy         string            `json:"resolvedBy,omitempty"` // Final approver, rejecter or expirer
+	ResolvedAt         int64             `json:"resolvedAt,omitempty"`
+	Resolution         string            `json:"resolution,omitempty"` // Rejection reason, or the ID the operation produced
+	TxID               string            `json:"txId"`                 // Requesting transaction
+}
+
+// ToJSON converts PendingAction to JSON bytes
+func (a *PendingAction) ToJSON() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// DisposalMethod describes how evidence was destroyed
+type DisposalMethod string
+
+const (
+	DisposalPhysicalDestruction DisposalMethod = "PHYSICAL_DESTRUCTION"  // Media shredded, incinerated or degaussed
+	DisposalSecureWipe          DisposalMethod = "SECURE_WIPE"           // Media overwritten
+	DisposalCryptoErasure       DisposalMethod = "CRYPTOGRAPHIC_ERASURE" // Encryption keys destroyed
+)
+
+// DisposalCertificate records the destruction of an evidence item
+// CertificateHash is the SHA-256 of the certificate's JSON with CertificateHash
+// empty, so a printed copy can be checked against the ledger.
+type DisposalCertificate struct {
+	DocType         string         `json:"docType"`
+	CertificateID   string         `json:"certificateId"`
+	EvidenceID      string         `json:"evidenceId"`
+	CaseID          string         `json:"caseId"`
+	EvidenceHash    string         `json:"evidenceHash"`       // Primary digest of the destroyed item
+	IPFSHash        string         `json:"ipfsHash,omitempty"` // CID that was unpinned
+	Method          DisposalMethod `json:"method"`
+	LegalAuthority  string         `json:"legalAuthority"`  // Court order or statute authorizing disposal
+	WitnessID       string         `json:"witnessId"`       // Identity that witnessed the destruction
+	CopiesDestroyed bool           `json:"copiesDestroyed"` // Disposer confirmed every stored copy was destroyed
+	IPFSUnpinned    bool           `json:"ipfsUnpinned"`    // Disposer confirmed the IPFS content was unpinned
+	Notes           string         `json:"notes,omitempty"`
+	DisposedBy      string         `json:"disposedBy"`
+	DisposerOrg     string         `json:"disposerOrg"`
+	DisposerRole    Role           `json:"disposerRole"`
+	DisposedAt      int64          `json:"disposedAt"`
+	ActionID        string         `json:"actionId,omitempty"`  // Approved PendingAction, if approval was required
+	Approvers       []string       `json:"approvers,omitempty"` // Identities that approved the disposal
+	TxID            string         `json:"txId"`
+	CertificateHash string         `json:"certificateHash"`
+}
+
+// ToJSON converts DisposalCertificate to JSON bytes
+func (c *DisposalCertificate) ToJSON() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// Classification is a security classification label, also used for clearances
+// Levels are ordered; see classificationRank.
+type Classification string
+
+const (
+	ClassUnclassified Classification = "UNCLASSIFIED" // Default; no attribute checks
+	ClassRestricted   Classification = "RESTRICTED"
+	ClassConfidential Classification = "CONFIDENTIAL"
+	ClassSecret       Classification = "SECRET"
+)
+
+// CaseStatus represents the state of an investigation case
+type CaseStatus string
+
+const (
+	CaseOpen      CaseStatus = "OPEN"      // Registered, no work assigned yet
+	CaseActive    CaseStatus = "ACTIVE"    // Under active investigation
+	CaseSuspended CaseStatus = "SUSPENDED" // On hold; no new evidence accepted
+	CaseClosed    CaseStatus = "CLOSED"    // Concluded (terminal)
+)
+
+// CaseAssignment records an identity assigned to work on a case
+type CaseAssignment struct {
+	EntityID   string `json:"entityId"`   // Assigned identity
+	OrgMSP     string `json:"orgMsp"`     // Organization of the assignee
+	Role       Role   `json:"role"`       // Role on the case
+	AssignedBy string `json:"assignedBy"` // Who made the assignment
+	AssignedAt int64  `json:"assignedAt"` // Assignment timestamp
+}
+
+// Case represents an investigation that evidence is collected for
+type Case struct {
+	DocType          string           `json:"docType"`          // For CouchDB queries
+	CaseID           string           `json:"caseId"`           // Unique case identifier
+	Title            string           `json:"title"`            // Short case title
+	Description      string           `json:"description"`      // Case summary
+	Jurisdiction     string           `json:"jurisdiction"`     // Court or agency jurisdiction
+	LeadInvestigator string           `json:"leadInvestigator"` // Lead investigator identity
+	Status           CaseStatus       `json:"status"`           // OPEN, ACTIVE, SUSPENDED, CLOSED
+	Assignments      []CaseAssignment `json:"assignments"`      // Identities working on the case
+	OpenedBy         string           `json:"openedBy"`         // Who registered the case
+	OpenedOrg        string           `json:"openedOrg"`        // Organization that owns the case
+	OpenedAt         int64            `json:"openedAt"`         // Registration timestamp
+	UpdatedAt        int64            `json:"updatedAt"`        // Last change timestamp
+	StatusReason     string           `json:"statusReason"`     // Reason for the last status change
+	ClosedBy         string           `json:"closedBy,omitempty"` // Who closed the case
+	ClosedAt         int64            `json:"closedAt,omitempty"` // Closure timestamp
+	Classification   Classification   `json:"classification,omitempty"` // Label applied to all evidence in the case
+	Units            []string         `json:"units,omitempty"`    // Units cleared for the case (empty: any unit)
+}
+
+// ToJSON converts Case to JSON bytes
+func (c *Case) ToJSON() ([]byte, error) {
+	return json.Marshal(c)
 }
 
+// CaseEvidence is a case header together with the evidence registered under it
+type CaseEvidence struct {
+	Case     *Case      `json:"case"`     // Case record (nil for legacy free-text case IDs)
+	Evidence []Evidence `json:"evidence"` // Evidence in the case
+}
+
+// AccessRequestStatus represents the state of an access request
+type AccessRequestStatus string
+
+const (
+	AccessPending  AccessRequestStatus = "PENDING"  // Awaiting decision
+	AccessApproved AccessRequestStatus = "APPROVED" // Granted until ExpiresAt
+	AccessDenied   AccessRequestStatus = "DENIED"   // Refused (terminal)
+	AccessRevoked  AccessRequestStatus = "REVOKED"  // Withdrawn before expiry (terminal)
+	AccessExpired  AccessRequestStatus = "EXPIRED"  // Lapsed at ExpiresAt (terminal)
+)
+
 // AccessRequest represents a request to access evidence
 type AccessRequest struct {
 	DocType       string `json:"docType"`       // For CouchDB queries
@@ -123,11 +624,18 @@ type AccessRequest struct {
 	RequesterRole Role   `json:"requesterRole"` // Role of requester
 	Purpose       string `json:"purpose"`       // Stated purpose for access
 	RequestedAt   int64  `json:"requestedAt"`   // Request timestamp
-	Status        string `json:"status"`        // PENDING, APPROVED, DENIED
+	Status        AccessRequestStatus `json:"status"` // PENDING, APPROVED, DENIED, REVOKED, EXPIRED
 	ApprovedBy    string `json:"approvedBy"`    // Approver (if approved)
 	ApprovedAt    int64  `json:"approvedAt"`    // Approval timestamp
 	DenialReason  string `json:"denialReason"`  // Reason if denied
 	ExpiresAt     int64  `json:"expiresAt"`     // Access expiration time
+	ExtendedBy    string `json:"extendedBy,omitempty"`       // Last approver to extend access
+	ExtendedAt    int64  `json:"extendedAt,omitempty"`       // Last extension timestamp
+	RevokedBy     string `json:"revokedBy,omitempty"`        // Who revoked access
+	RevokedAt     int64  `json:"revokedAt,omitempty"`        // Revocation timestamp
+	RevocationReason string `json:"revocationReason,omitempty"` // Reason for revocation
+	ExpiredAt     int64  `json:"expiredAt,omitempty"`        // When the grant was marked expired
+	KeyReleaseID  string `json:"keyReleaseId,omitempty"`     // KeyRelease issued against this grant
 }
 
 // AnalysisRecord represents a forensic analysis session
@@ -158,10 +666,13 @@ type JudicialReview struct {
 	CaseID          string `json:"caseId"`          // Court case identifier
 	SubmittedBy     string `json:"submittedBy"`     // Who submitted for review
 	SubmittedOrg    string `json:"submittedOrg"`    // Organization of submitter
+	CourtOrg        string `json:"courtOrg,omitempty"` // Court organization the review was sent to
 	SubmittedAt     int64  `json:"submittedAt"`     // Submission timestamp
-	CaseNotes       string `json:"caseNotes"`       // Notes for the court
+	CaseNotes       string `json:"caseNotes"`       // Notes for the court (legacy; now sealed)
+	CaseNotesHash   string `json:"caseNotesHash"`   // SHA-256 of the sealed case notes
 	Decision        string `json:"decision"`        // ADMITTED, REJECTED, PENDING
-	DecisionReason  string `json:"decisionReason"`  // Reasoning for decision
+	DecisionReason  string `json:"decisionReason"`  // Reasoning for decision (legacy; now sealed)
+	DecisionReasonHash string `json:"decisionReasonHash"` // SHA-256 of the sealed decision reason
 	DecidedBy       string `json:"decidedBy"`       // Judge/counsel who decided
 	DecidedAt       int64  `json:"decidedAt"`       // Decision timestamp
 	CourtReference  string `json:"courtReference"`  // Court document reference
@@ -178,7 +689,59 @@ type AuditReport struct {
 	GeneratedAt    int64          `json:"generatedAt"`    // Report generation time
 	GeneratedBy    string         `json:"generatedBy"`    // Who generated the report
 	IntegrityHash  string         `json:"integrityHash"`  // Hash of report contents
-	Verified       bool           `json:"verified"`       // All events verified
+	ChainVerification *ChainVerificationResult `json:"chainVerification"` // Custody hash chain check
+	Verified       bool           `json:"verified"`       // Integrity and custody chain verified
+}
+
+// Lineage relations of a node to the evidence a lineage was requested for
+const (
+	LineageSelf       = "SELF"
+	LineageAncestor   = "ANCESTOR"
+	LineageDescendant = "DESCENDANT"
+)
+
+// LineageNode is one evidence item in a derivation lineage
+type LineageNode struct {
+	EvidenceID        string         `json:"evidenceId"`           // Evidence item
+	CaseID            string         `json:"caseId"`               // Case of the item
+	EvidenceHash      string         `json:"evidenceHash"`         // Hash of the item's content
+	Digests           []Digest       `json:"digests"`              // Algorithm-tagged digests of the content
+	Status            EvidenceStatus `json:"status"`               // Current status
+	Relation          string         `json:"relation"`             // SELF, ANCESTOR or DESCENDANT
+	Depth             int            `json:"depth"`                // Derivation steps from the requested item
+	ParentEvidenceIDs []string       `json:"parentEvidenceIds"`    // Direct parents
+	ChildEvidenceIDs  []string       `json:"childEvidenceIds"`     // Direct children
+	Derivation        *Derivation    `json:"derivation,omitempty"` // How the item was derived
+	RegisteredAt      int64          `json:"registeredAt"`         // Registration timestamp
+	Redacted          bool           `json:"redacted,omitempty"`   // Hashes and derivation withheld: caller fails the ABAC checks
+}
+
+// EvidenceLineage is the ancestor and descendant graph of an evidence item
+type EvidenceLineage struct {
+	EvidenceID string        `json:"evidenceId"` // Evidence the lineage was requested for
+	Origins    []string      `json:"origins"`    // Ancestors (or self) with no parents: the original acquisitions
+	Nodes      []LineageNode `json:"nodes"`      // Self, then ancestors and descendants by depth
+}
+
+// PaginatedEvidence is one page of an evidence list query
+type PaginatedEvidence struct {
+	Records             []Evidence `json:"records"`             // Evidence on this page
+	FetchedRecordsCount int32      `json:"fetchedRecordsCount"` // Number of records fetched
+	Bookmark            string     `json:"bookmark"`            // Pass to the next call to continue
+}
+
+// PaginatedCustodyEvents is one page of a custody history query
+type PaginatedCustodyEvents struct {
+	Records             []CustodyEvent `json:"records"`             // Events on this page
+	FetchedRecordsCount int32          `json:"fetchedRecordsCount"` // Number of records fetched
+	Bookmark            string         `json:"bookmark"`            // Pass to the next call to continue
+}
+
+// PaginatedAnalysisRecords is one page of an analysis record query
+type PaginatedAnalysisRecords struct {
+	Records             []AnalysisRecord `json:"records"`             // Analysis records on this page
+	FetchedRecordsCount int32            `json:"fetchedRecordsCount"` // Number of records fetched
+	Bookmark            string           `json:"bookmark"`            // Pass to the next call to continue
 }
 
 // SensitiveMetadata stored in private data collection
@@ -193,6 +756,73 @@ type SensitiveMetadata struct {
 	ClassificationLevel string `json:"classificationLevel"` // Security classification
 }
 
+// SensitiveAccessRecord is the logged, time-limited permission to read sensitive metadata
+// Design Decision: Whatever a submitted transaction returns is written to the
+// block for every channel member, so the metadata itself is only returned by
+// the evaluated GetSensitiveMetadata. That query cannot log the read, so it
+// answers only callers whose access to the stored version was first recorded
+// by RecordSensitiveMetadataAccess.
+type SensitiveAccessRecord struct {
+	DocType     string `json:"docType"`
+	EvidenceID  string `json:"evidenceId"`
+	AccessorID  string `json:"accessorId"`
+	AccessorOrg string `json:"accessorOrg"`
+	DataHash    string `json:"dataHash"` // SensitiveMetadataHash of the version the access covers
+	RecordedAt  int64  `json:"recordedAt"`
+	ExpiresAt   int64  `json:"expiresAt"` // GetSensitiveMetadata refuses the caller after this
+	TxID        string `json:"txId"`
+}
+
+// EncryptionKeyEscrow is a wrapped evidence encryption key stored in the
+// encryptionKeys private data collection
+// Design Decision: The key is wrapped (encrypted) by the backend before it is
+// escrowed; the ledger controls who may obtain the wrapped key.
+type EncryptionKeyEscrow struct {
+	EvidenceID string `json:"evidenceId"` // Evidence the key decrypts
+	KeyID      string `json:"keyId"`      // Matches Evidence.EncryptionKeyID
+	WrappedKey string `json:"wrappedKey"` // Base64 wrapped key material
+	Algorithm  string `json:"algorithm"`  // Encryption algorithm (e.g. AES-256-GCM)
+	StoredBy   string `json:"storedBy"`   // Who escrowed the key
+	StoredOrg  string `json:"storedOrg"`  // Organization of the depositor
+	StoredAt   int64  `json:"storedAt"`   // Escrow timestamp
+}
+
+// KeyRelease is the receipt for an escrowed key released against an access request
+// The key itself is read with the evaluated GetReleasedEncryptionKey for as
+// long as the request stays approved and unexpired.
+type KeyRelease struct {
+	DocType     string `json:"docType"`
+	ReleaseID   string `json:"releaseId"`
+	RequestID   string `json:"requestId"`
+	EvidenceID  string `json:"evidenceId"`
+	KeyID       string `json:"keyId"`
+	KeyHash     string `json:"keyHash"` // EncryptionKeyHash of the escrow released
+	ReleasedTo  string `json:"releasedTo"`
+	ReleasedOrg string `json:"releasedOrg"`
+	ReleasedAt  int64  `json:"releasedAt"`
+	TxID        string `json:"txId"`
+}
+
+// NoteKind identifies which part of a judicial review a sealed note belongs to
+type NoteKind string
+
+const (
+	NoteKindCaseNotes      NoteKind = "CASE_NOTES"
+	NoteKindDecisionReason NoteKind = "DECISION_REASON"
+)
+
+// SealedJudicialNote is a judicial review note stored in the judicialNotes
+// private data collection (readable only by JudiciaryMSP)
+type SealedJudicialNote struct {
+	ReviewID   string   `json:"reviewId"`   // Review the note belongs to
+	EvidenceID string   `json:"evidenceId"` // Evidence under review
+	Kind       NoteKind `json:"kind"`       // CASE_NOTES or DECISION_REASON
+	Notes      string   `json:"notes"`      // Note contents
+	SealedBy   string   `json:"sealedBy"`   // Who wrote the note
+	SealedOrg  string   `json:"sealedOrg"`  // Organization of the author
+	SealedAt   int64    `json:"sealedAt"`   // When the note was sealed
+}
+
 // Helper methods
 
 // ToJSON converts Evidence to JSON bytes
@@ -225,12 +855,30 @@ func (r *AuditReport) ToJSON() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+// Private data collection names (see fabric-network/collections_config.json)
+const (
+	CollectionSensitiveMetadata = "sensitiveEvidenceMetadata"
+	CollectionEncryptionKeys    = "encryptionKeys"
+	CollectionJudicialNotes     = "judicialNotes"
+)
+
 // Document type constants for CouchDB queries
 const (
-	DocTypeEvidence       = "evidence"
-	DocTypeCustodyEvent   = "custody_event"
-	DocTypeAccessRequest  = "access_request"
-	DocTypeAnalysisRecord = "analysis_record"
-	DocTypeJudicialReview = "judicial_review"
+	DocTypeEvidence             = "evidence"
+	DocTypeCustodyEvent         = "custody_event"
+	DocTypeAccessRequest        = "access_request"
+	DocTypeAnalysisRecord       = "analysis_record"
+	DocTypeJudicialReview       = "judicial_review"
+	DocTypeEventCounter         = "event_counter"
+	DocTypeCustodyTransfer      = "custody_transfer"
+	DocTypeCase                 = "case"
+	DocTypeIntegrityAttestation = "integrity_attestation"
+	DocTypeLifecycleConfig      = "lifecycle_config"
+	DocTypePermissionPolicy     = "permission_policy"
+	DocTypePolicyProposal       = "policy_proposal"
+	DocTypePendingAction        = "pending_action"
+	DocTypeDisposalCertificate  = "disposal_certificate"
+	DocTypeSensitiveAccess      = "sensitive_access"
+	DocTypeKeyRelease           = "key_release"
 )
 
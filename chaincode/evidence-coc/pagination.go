@@ -0,0 +1,239 @@
+// Copyright Evidentia Chain-of-Custody System
+// Paginated list queries
+//
+// Design Decision: The unpaginated queries load every match into memory and time
+// out on large ledgers. These variants use GetQueryResultWithPagination with an
+// explicit sort backed by the CouchDB indexes in META-INF/statedb/couchdb/indexes,
+// so page boundaries are stable between calls.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// maxPageSize bounds a single page to keep query time predictable
+const maxPageSize int32 = 200
+
+// pagedQuery runs a sorted CouchDB selector query and returns one page of results
+// All sort fields use the same direction; the fields must match a deployed index.
+func pagedQuery(
+	ctx contractapi.TransactionContextInterface,
+	selector map[string]interface{},
+	sortFields []string,
+	descending bool,
+	pageSize int32,
+	bookmark string,
+) (shim.StateQueryIteratorInterface, int32, string, error) {
+	if pageSize <= 0 || pageSize > maxPageSize {
+		return nil, 0, "", fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+	}
+
+	direction := "asc"
+	if descending {
+		direction = "desc"
+	}
+	sort := make([]map[string]string, 0, len(sortFields))
+	for _, field := range sortFields {
+		sort = append(sort, map[string]string{field: direction})
+	}
+
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector": selector,
+		"sort":     sort,
+	})
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryJSON), pageSize, bookmark)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return resultsIterator, metadata.GetFetchedRecordsCount(), metadata.GetBookmark(), nil
+}
+
+// pagedEvidenceQuery returns one page of evidence matching a selector, redacted for the caller
+// Items the caller's attributes do not clear are left out of the page.
+func pagedEvidenceQuery(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	selector map[string]interface{},
+	sortFields []string,
+	descending bool,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedEvidence, error) {
+	resultsIterator, fetched, nextBookmark, err := pagedQuery(ctx, selector, sortFields, descending, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	evidenceList := []Evidence{}
+	cases := map[string]*Case{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var evidence Evidence
+		if err := json.Unmarshal(queryResult.Value, &evidence); err != nil {
+			continue
+		}
+		if checkEvidenceAttributes(ctx, identity, &evidence, cases) != nil {
+			continue
+		}
+		if err := applyContentAccess(ctx, identity, &evidence); err != nil {
+			return nil, err
+		}
+		evidenceList = append(evidenceList, evidence)
+	}
+
+	return &PaginatedEvidence{
+		Records:             evidenceList,
+		FetchedRecordsCount: fetched,
+		Bookmark:            nextBookmark,
+	}, nil
+}
+
+// GetAllEvidencePaginated retrieves one page of all evidence, oldest first
+func (s *EvidenceContract) GetAllEvidencePaginated(
+	ctx contractapi.TransactionContextInterface,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedEvidence, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := map[string]interface{}{"docType": DocTypeEvidence}
+	return pagedEvidenceQuery(ctx, identity, selector, []string{"docType", "createdAt", "id"}, false, pageSize, bookmark)
+}
+
+// GetEvidenceByCasePaginated retrieves one page of evidence for a case, oldest first
+func (s *EvidenceContract) GetEvidenceByCasePaginated(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedEvidence, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := map[string]interface{}{"docType": DocTypeEvidence, "caseId": caseID}
+	return pagedEvidenceQuery(ctx, identity, selector, []string{"docType", "caseId", "createdAt", "id"}, false, pageSize, bookmark)
+}
+
+// QueryByStatusPaginated retrieves one page of evidence with a status, oldest first
+func (s *EvidenceContract) QueryByStatusPaginated(
+	ctx contractapi.TransactionContextInterface,
+	status string,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedEvidence, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	selector := map[string]interface{}{"docType": DocTypeEvidence, "status": status}
+	return pagedEvidenceQuery(ctx, identity, selector, []string{"docType", "status", "createdAt", "id"}, false, pageSize, bookmark)
+}
+
+// GetEvidenceHistoryPaginated retrieves one page of the custody chain, in order
+// Verified is not set on paged events; use VerifyCustodyChain for the whole chain.
+func (s *EvidenceContract) GetEvidenceHistoryPaginated(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedCustodyEvents, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	selector := map[string]interface{}{"docType": DocTypeCustodyEvent, "evidenceId": evidenceID}
+	resultsIterator, fetched, nextBookmark, err := pagedQuery(ctx, selector, []string{"docType", "evidenceId", "timestamp", "sequence"}, false, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	events := []CustodyEvent{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var event CustodyEvent
+		if err := json.Unmarshal(queryResult.Value, &event); err != nil {
+			continue
+		}
+		event.Verified = false
+		events = append(events, event)
+	}
+
+	return &PaginatedCustodyEvents{
+		Records:             events,
+		FetchedRecordsCount: fetched,
+		Bookmark:            nextBookmark,
+	}, nil
+}
+
+// GetAnalysisRecordsPaginated retrieves one page of analysis records for evidence, oldest first
+func (s *EvidenceContract) GetAnalysisRecordsPaginated(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+	pageSize int32,
+	bookmark string,
+) (*PaginatedAnalysisRecords, error) {
+	identity, err := RequirePermission(ctx, PermViewAudit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getEvidenceForCaller(ctx, identity, evidenceID); err != nil {
+		return nil, err
+	}
+
+	selector := map[string]interface{}{"docType": DocTypeAnalysisRecord, "evidenceId": evidenceID}
+	resultsIterator, fetched, nextBookmark, err := pagedQuery(ctx, selector, []string{"docType", "evidenceId", "startTime", "analysisId"}, false, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	records := []AnalysisRecord{}
+	for resultsIterator.HasNext() {
+		queryResult, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record AnalysisRecord
+		if err := json.Unmarshal(queryResult.Value, &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return &PaginatedAnalysisRecords{
+		Records:             records,
+		FetchedRecordsCount: fetched,
+		Bookmark:            nextBookmark,
+	}, nil
+}
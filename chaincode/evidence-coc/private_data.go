@@ -0,0 +1,717 @@
+// Copyright Evidentia Chain-of-Custody System
+// Private data collection operations
+//
+// Design Decision: Sensitive values are passed through the transient map so they
+// never appear in the transaction proposal that is written to the block. Only a
+// hash of the private value is kept in world state, which lets every org verify
+// the private copy without being able to read it. For the same reason private
+// values are only returned by evaluated queries: a submitted transaction's
+// response is written to the block along with its proposal.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// Transient map keys
+const (
+	transientSensitiveMetadata = "sensitiveMetadata"
+	transientEncryptionKey     = "encryptionKey"
+	transientCaseNotes         = "caseNotes"
+	transientDecisionReason    = "decisionReason"
+)
+
+// getTransientField reads a required field from the transient map
+func getTransientField(ctx contractapi.TransactionContextInterface, field string) ([]byte, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient map: %v", err)
+	}
+
+	value, ok := transientMap[field]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("transient field %s is required", field)
+	}
+
+	return value, nil
+}
+
+// getOptionalTransientField reads a field from the transient map, returning nil if absent
+func getOptionalTransientField(ctx contractapi.TransactionContextInterface, field string) ([]byte, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient map: %v", err)
+	}
+	return transientMap[field], nil
+}
+
+// =============================================================================
+// Sensitive Evidence Metadata
+// =============================================================================
+
+// PutSensitiveMetadata stores victim/suspect/witness information for evidence
+// in the sensitiveEvidenceMetadata collection
+// Transient map:
+//   - sensitiveMetadata: JSON string containing SensitiveMetadata
+func (s *EvidenceContract) PutSensitiveMetadata(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) error {
+	identity, err := RequirePermission(ctx, PermManageSensitive)
+	if err != nil {
+		return err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return err
+	}
+	if err := RequireNotDisposed(evidence); err != nil {
+		return err
+	}
+
+	// Only the custodian organization can write the sensitive metadata
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can store sensitive metadata")
+	}
+
+	metadataJSON, err := getTransientField(ctx, transientSensitiveMetadata)
+	if err != nil {
+		return err
+	}
+
+	var metadata SensitiveMetadata
+	if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+		return fmt.Errorf("failed to parse sensitive metadata: %v", err)
+	}
+	if metadata.EvidenceID != "" && metadata.EvidenceID != evidenceID {
+		return fmt.Errorf("sensitive metadata is for evidence %s, not %s", metadata.EvidenceID, evidenceID)
+	}
+	metadata.EvidenceID = evidenceID
+
+	// The writer must be cleared for the label they put on the metadata
+	level, err := parseClassification(metadata.ClassificationLevel)
+	if err != nil {
+		return err
+	}
+	if err := requireSensitiveClearance(identity, evidenceID, string(level)); err != nil {
+		return err
+	}
+	metadata.ClassificationLevel = string(level)
+
+	// Re-marshal so the stored bytes (and therefore the hash) are canonical
+	privateJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(CollectionSensitiveMetadata, evidenceID, privateJSON); err != nil {
+		return fmt.Errorf("failed to store sensitive metadata: %v", err)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Keep the private data hash and label on the public record
+	dataHash := HashData(privateJSON)
+	evidence.SensitiveMetadataHash = dataHash
+	evidence.SensitiveClassification = level
+	evidence.UpdatedAt = timestamp
+
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
+
+	// Record event (without the private contents)
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventSensitiveUpdate,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        "Sensitive metadata updated",
+		Details:       fmt.Sprintf(`{"collection":"%s","classificationLevel":"%s","dataHash":"%s"}`, CollectionSensitiveMetadata, level, dataHash),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
+
+// sensitiveAccessWindow is how long a recorded access lets the accessor read sensitive metadata (seconds)
+const sensitiveAccessWindow int64 = 15 * 60
+
+// sensitiveAccessKey returns the world state key of an identity's access record for evidence
+func sensitiveAccessKey(ctx contractapi.TransactionContextInterface, evidenceID string, accessorID string) (string, error) {
+	return ctx.GetStub().CreateCompositeKey("SACCESS", []string{evidenceID, accessorID})
+}
+
+// getVerifiedSensitiveMetadata reads the private metadata of evidence and checks it against the public hash
+func getVerifiedSensitiveMetadata(ctx contractapi.TransactionContextInterface, evidence *Evidence) ([]byte, error) {
+	privateJSON, err := ctx.GetStub().GetPrivateData(CollectionSensitiveMetadata, evidence.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sensitive metadata: %v", err)
+	}
+	if privateJSON == nil {
+		return nil, fmt.Errorf("no sensitive metadata stored for evidence %s", evidence.ID)
+	}
+	if HashData(privateJSON) != evidence.SensitiveMetadataHash {
+		return nil, fmt.Errorf("sensitive metadata for evidence %s does not match the recorded hash", evidence.ID)
+	}
+	return privateJSON, nil
+}
+
+// RecordSensitiveMetadataAccess logs the caller's access to the sensitive metadata of evidence
+// The custody event is the ledger's record of the read; the metadata itself is
+// then read with GetSensitiveMetadata, which must be evaluated, for the next
+// sensitiveAccessWindow seconds. Nothing private is returned.
+func (s *EvidenceContract) RecordSensitiveMetadataAccess(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*SensitiveAccessRecord, error) {
+	identity, err := RequirePermission(ctx, PermViewSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateJSON, err := getVerifiedSensitiveMetadata(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata SensitiveMetadata
+	if err := json.Unmarshal(privateJSON, &metadata); err != nil {
+		return nil, err
+	}
+	if err := requireSensitiveClearance(identity, evidenceID, metadata.ClassificationLevel); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &SensitiveAccessRecord{
+		DocType:     DocTypeSensitiveAccess,
+		EvidenceID:  evidenceID,
+		AccessorID:  identity.ID,
+		AccessorOrg: identity.MSPID,
+		DataHash:    evidence.SensitiveMetadataHash,
+		RecordedAt:  timestamp,
+		ExpiresAt:   timestamp + sensitiveAccessWindow,
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+
+	recordKey, err := sensitiveAccessKey(ctx, evidenceID, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(recordKey, recordJSON); err != nil {
+		return nil, fmt.Errorf("failed to record sensitive metadata access: %v", err)
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventSensitiveAccess,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        "Sensitive metadata accessed",
+		Details:       fmt.Sprintf(`{"collection":"%s","classificationLevel":"%s","dataHash":"%s","expiresAt":%d}`, CollectionSensitiveMetadata, metadata.ClassificationLevel, record.DataHash, record.ExpiresAt),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetSensitiveMetadata reads sensitive metadata from the private collection
+// Design Decision: This must be evaluated, never submitted, or the metadata is
+// written to the block. Because an evaluation leaves no trace, the caller must
+// first record the access with RecordSensitiveMetadataAccess; the record must be
+// unexpired and cover the version currently stored.
+func (s *EvidenceContract) GetSensitiveMetadata(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) (*SensitiveMetadata, error) {
+	identity, err := RequirePermission(ctx, PermViewSensitive)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordKey, err := sensitiveAccessKey(ctx, evidenceID, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+	recordJSON, err := ctx.GetStub().GetState(recordKey)
+	if err != nil {
+		return nil, err
+	}
+	if recordJSON == nil {
+		return nil, fmt.Errorf("no recorded access to the sensitive metadata of evidence %s: call RecordSensitiveMetadataAccess first", evidenceID)
+	}
+
+	var record SensitiveAccessRecord
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record.ExpiresAt <= timestamp {
+		return nil, fmt.Errorf("recorded access to the sensitive metadata of evidence %s expired at %s", evidenceID, FormatTimestamp(record.ExpiresAt))
+	}
+	if record.DataHash != evidence.SensitiveMetadataHash {
+		return nil, fmt.Errorf("sensitive metadata of evidence %s changed after the access was recorded", evidenceID)
+	}
+
+	privateJSON, err := getVerifiedSensitiveMetadata(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata SensitiveMetadata
+	if err := json.Unmarshal(privateJSON, &metadata); err != nil {
+		return nil, err
+	}
+	if err := requireSensitiveClearance(identity, evidenceID, metadata.ClassificationLevel); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// =============================================================================
+// Encryption Key Escrow
+// =============================================================================
+
+// StoreEncryptionKey escrows the wrapped encryption key of evidence in the
+// encryptionKeys collection
+// Transient map:
+//   - encryptionKey: JSON string containing EncryptionKeyEscrow (keyId, wrappedKey, algorithm)
+func (s *EvidenceContract) StoreEncryptionKey(
+	ctx contractapi.TransactionContextInterface,
+	evidenceID string,
+) error {
+	identity, err := RequirePermission(ctx, PermRegisterEvidence)
+	if err != nil {
+		return err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, evidenceID)
+	if err != nil {
+		return err
+	}
+	if err := RequireNotDisposed(evidence); err != nil {
+		return err
+	}
+
+	// Only the custodian organization can escrow the key
+	if identity.MSPID != evidence.CurrentOrg {
+		return fmt.Errorf("only current custodian organization can store the encryption key")
+	}
+
+	keyJSON, err := getTransientField(ctx, transientEncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	var escrow EncryptionKeyEscrow
+	if err := json.Unmarshal(keyJSON, &escrow); err != nil {
+		return fmt.Errorf("failed to parse encryption key: %v", err)
+	}
+	if escrow.WrappedKey == "" {
+		return fmt.Errorf("wrapped key is required")
+	}
+	if escrow.KeyID == "" {
+		escrow.KeyID = evidence.EncryptionKeyID
+	}
+	if escrow.KeyID != evidence.EncryptionKeyID {
+		return fmt.Errorf("key %s does not match evidence key reference %s", escrow.KeyID, evidence.EncryptionKeyID)
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	escrow.EvidenceID = evidenceID
+	escrow.StoredBy = identity.ID
+	escrow.StoredOrg = identity.MSPID
+	escrow.StoredAt = timestamp
+
+	privateJSON, err := json.Marshal(escrow)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(CollectionEncryptionKeys, evidenceID, privateJSON); err != nil {
+		return fmt.Errorf("failed to store encryption key: %v", err)
+	}
+
+	dataHash := HashData(privateJSON)
+	evidence.EncryptionKeyHash = dataHash
+	evidence.UpdatedAt = timestamp
+
+	if err := putEvidence(ctx, evidence); err != nil {
+		return err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    evidenceID,
+		EventType:     EventKeyEscrowed,
+		FromEntity:    identity.ID,
+		FromOrg:       identity.MSPID,
+		Reason:        "Encryption key escrowed",
+		Details:       fmt.Sprintf(`{"collection":"%s","keyId":"%s","dataHash":"%s"}`, CollectionEncryptionKeys, escrow.KeyID, dataHash),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+
+	return recordCustodyEvent(ctx, &event)
+}
+
+// getReleasableRequest reads an access request and checks it is the caller's, approved and unexpired
+func getReleasableRequest(
+	ctx contractapi.TransactionContextInterface,
+	identity *ClientIdentity,
+	requestID string,
+	timestamp int64,
+) (*AccessRequest, error) {
+	request, err := getAccessRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if request.RequesterID != identity.ID {
+		return nil, fmt.Errorf("access request %s belongs to another requester", requestID)
+	}
+	if request.Status != AccessApproved {
+		return nil, fmt.Errorf("access request %s is not approved", requestID)
+	}
+	if request.ExpiresAt <= timestamp {
+		return nil, fmt.Errorf("access request %s expired at %s", requestID, FormatTimestamp(request.ExpiresAt))
+	}
+	return request, nil
+}
+
+// getVerifiedKeyEscrow reads the escrowed key of evidence and checks it against the public hash
+func getVerifiedKeyEscrow(ctx contractapi.TransactionContextInterface, evidence *Evidence) (*EncryptionKeyEscrow, error) {
+	privateJSON, err := ctx.GetStub().GetPrivateData(CollectionEncryptionKeys, evidence.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %v", err)
+	}
+	if privateJSON == nil {
+		return nil, fmt.Errorf("no encryption key escrowed for evidence %s", evidence.ID)
+	}
+	if HashData(privateJSON) != evidence.EncryptionKeyHash {
+		return nil, fmt.Errorf("escrowed key for evidence %s does not match the recorded hash", evidence.ID)
+	}
+
+	var escrow EncryptionKeyEscrow
+	if err := json.Unmarshal(privateJSON, &escrow); err != nil {
+		return nil, err
+	}
+	return &escrow, nil
+}
+
+// ReleaseEncryptionKey releases the escrowed key of evidence to the holder of an
+// approved, unexpired access request
+// Returns a KeyRelease receipt, not the key: the key is then read with the
+// evaluated GetReleasedEncryptionKey, so it never appears in a block.
+func (s *EvidenceContract) ReleaseEncryptionKey(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+) (*KeyRelease, error) {
+	identity, err := RequirePermission(ctx, PermRequestAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := getReleasableRequest(ctx, identity, requestID, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, request.EvidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSensitiveClearance(identity, evidence.ID, string(evidence.SensitiveClassification)); err != nil {
+		return nil, err
+	}
+
+	escrow, err := getVerifiedKeyEscrow(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	release := &KeyRelease{
+		DocType:     DocTypeKeyRelease,
+		ReleaseID:   GenerateID(ctx, "KRL", requestID),
+		RequestID:   requestID,
+		EvidenceID:  request.EvidenceID,
+		KeyID:       escrow.KeyID,
+		KeyHash:     evidence.EncryptionKeyHash,
+		ReleasedTo:  identity.ID,
+		ReleasedOrg: identity.MSPID,
+		ReleasedAt:  timestamp,
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	releaseJSON, err := json.Marshal(release)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(release.ReleaseID, releaseJSON); err != nil {
+		return nil, fmt.Errorf("failed to store key release: %v", err)
+	}
+
+	request.KeyReleaseID = release.ReleaseID
+	if err := putAccessRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	event := CustodyEvent{
+		DocType:       DocTypeCustodyEvent,
+		EvidenceID:    request.EvidenceID,
+		EventType:     EventKeyReleased,
+		FromEntity:    evidence.CurrentCustodian,
+		FromOrg:       evidence.CurrentOrg,
+		ToEntity:      identity.ID,
+		ToOrg:         identity.MSPID,
+		Reason:        fmt.Sprintf("Encryption key released for: %s", request.Purpose),
+		Details:       fmt.Sprintf(`{"requestId":"%s","releaseId":"%s","keyId":"%s","expiresAt":%d}`, requestID, release.ReleaseID, escrow.KeyID, request.ExpiresAt),
+		Timestamp:     timestamp,
+		PerformedBy:   identity.ID,
+		PerformerOrg:  identity.MSPID,
+		PerformerRole: identity.Role,
+		TxID:          ctx.GetStub().GetTxID(),
+	}
+	if err := recordCustodyEvent(ctx, &event); err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// GetReleasedEncryptionKey returns an escrowed key released with ReleaseEncryptionKey
+// Design Decision: This must be evaluated, never submitted, or the key is
+// written to the block. It only answers while the access request is approved and
+// unexpired, and only for the escrow that was released; a key escrowed again
+// afterwards must be released again.
+func (s *EvidenceContract) GetReleasedEncryptionKey(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+) (*EncryptionKeyEscrow, error) {
+	identity, err := RequirePermission(ctx, PermRequestAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := GetTxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := getReleasableRequest(ctx, identity, requestID, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	if request.KeyReleaseID == "" {
+		return nil, fmt.Errorf("no key has been released against access request %s: call ReleaseEncryptionKey first", requestID)
+	}
+
+	releaseJSON, err := ctx.GetStub().GetState(request.KeyReleaseID)
+	if err != nil {
+		return nil, err
+	}
+	if releaseJSON == nil {
+		return nil, fmt.Errorf("key release %s not found", request.KeyReleaseID)
+	}
+
+	var release KeyRelease
+	if err := json.Unmarshal(releaseJSON, &release); err != nil {
+		return nil, err
+	}
+
+	evidence, err := getEvidenceForCaller(ctx, identity, request.EvidenceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireSensitiveClearance(identity, evidence.ID, string(evidence.SensitiveClassification)); err != nil {
+		return nil, err
+	}
+	if release.KeyHash != evidence.EncryptionKeyHash {
+		return nil, fmt.Errorf("the key of evidence %s was escrowed again after release %s", request.EvidenceID, release.ReleaseID)
+	}
+
+	return getVerifiedKeyEscrow(ctx, evidence)
+}
+
+// =============================================================================
+// Sealed Judicial Notes
+// =============================================================================
+
+// judicialNoteKey returns the private data key of a sealed judicial note
+func judicialNoteKey(ctx contractapi.TransactionContextInterface, reviewID string, kind NoteKind) (string, error) {
+	return ctx.GetStub().CreateCompositeKey("JNOTE", []string{reviewID, string(kind)})
+}
+
+// rejectPlaintextNote refuses a review note passed as a transaction argument
+// Arguments are part of the proposal and so are written to the block; the
+// deprecated argument is kept for signature compatibility and must be empty.
+func rejectPlaintextNote(argument string, value string, transientField string) error {
+	if value != "" {
+		return fmt.Errorf("%s must not be passed as an argument, where it is written to the block in cleartext: pass it in the transient map as %q", argument, transientField)
+	}
+	return nil
+}
+
+// sealJudicialNote stores a review note from the transient map in the
+// judicialNotes collection and returns its hash
+// Empty notes are not sealed.
+// Design Decision: judicialNotes allows non-members to write (memberOnlyWrite is
+// false) so that submitting organizations can seal notes they cannot read back.
+func sealJudicialNote(
+	ctx contractapi.TransactionContextInterface,
+	review *JudicialReview,
+	kind NoteKind,
+	transientField string,
+	identity *ClientIdentity,
+	timestamp int64,
+) (string, error) {
+	sealed, err := getOptionalTransientField(ctx, transientField)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) == 0 {
+		return "", nil
+	}
+	notes := string(sealed)
+
+	note := SealedJudicialNote{
+		ReviewID:   review.ReviewID,
+		EvidenceID: review.EvidenceID,
+		Kind:       kind,
+		Notes:      notes,
+		SealedBy:   identity.ID,
+		SealedOrg:  identity.MSPID,
+		SealedAt:   timestamp,
+	}
+
+	noteJSON, err := json.Marshal(note)
+	if err != nil {
+		return "", err
+	}
+
+	noteKey, err := judicialNoteKey(ctx, review.ReviewID, kind)
+	if err != nil {
+		return "", err
+	}
+	if err := ctx.GetStub().PutPrivateData(CollectionJudicialNotes, noteKey, noteJSON); err != nil {
+		return "", fmt.Errorf("failed to seal judicial note: %v", err)
+	}
+
+	return HashData(noteJSON), nil
+}
+
+// GetSealedJudicialNotes returns the sealed case notes and decision reason of a review
+// Only JudiciaryMSP members can read the judicialNotes collection.
+func (s *EvidenceContract) GetSealedJudicialNotes(
+	ctx contractapi.TransactionContextInterface,
+	reviewID string,
+) ([]SealedJudicialNote, error) {
+	_, err := RequirePermission(ctx, PermViewSealedNotes)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewJSON, err := ctx.GetStub().GetState(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if reviewJSON == nil {
+		return nil, fmt.Errorf("review %s not found", reviewID)
+	}
+
+	var review JudicialReview
+	if err := json.Unmarshal(reviewJSON, &review); err != nil {
+		return nil, err
+	}
+
+	expected := []struct {
+		kind NoteKind
+		hash string
+	}{
+		{NoteKindCaseNotes, review.CaseNotesHash},
+		{NoteKindDecisionReason, review.DecisionReasonHash},
+	}
+
+	notes := []SealedJudicialNote{}
+	for _, e := range expected {
+		if e.hash == "" {
+			continue
+		}
+
+		noteKey, err := judicialNoteKey(ctx, reviewID, e.kind)
+		if err != nil {
+			return nil, err
+		}
+		noteJSON, err := ctx.GetStub().GetPrivateData(CollectionJudicialNotes, noteKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sealed judicial note: %v", err)
+		}
+		if noteJSON == nil {
+			return nil, fmt.Errorf("sealed %s for review %s not found", e.kind, reviewID)
+		}
+		if HashData(noteJSON) != e.hash {
+			return nil, fmt.Errorf("sealed %s for review %s does not match the recorded hash", e.kind, reviewID)
+		}
+
+		var note SealedJudicialNote
+		if err := json.Unmarshal(noteJSON, &note); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+
+	return notes, nil
+}
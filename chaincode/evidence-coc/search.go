@@ -0,0 +1,164 @@
+// Copyright Evidentia Chain-of-Custody System
+// Multi-criteria evidence search and CouchDB selector construction
+//
+// Design Decision: Selectors used to be assembled with fmt.Sprintf, so a case ID
+// or status containing a quote could inject extra selector clauses. Every query
+// is now built as a Go map with fixed field names and serialized with
+// json.Marshal; caller input only ever appears as an escaped JSON value.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// defaultSearchPageSize is used when a search filter does not set a page size
+const defaultSearchPageSize int32 = 50
+
+// Evidence search sort keys
+const (
+	SearchSortCreatedAt       = "createdAt"
+	SearchSortUpdatedAt       = "updatedAt"
+	SearchSortAcquisitionDate = "acquisitionDate"
+)
+
+// searchSortFields maps each sort key to the fields of its CouchDB index
+var searchSortFields = map[string][]string{
+	SearchSortCreatedAt:       {"docType", "createdAt", "id"},
+	SearchSortUpdatedAt:       {"docType", "updatedAt", "id"},
+	SearchSortAcquisitionDate: {"docType", "metadata.acquisitionDate", "id"},
+}
+
+// EvidenceSearchFilter describes a SearchEvidence query
+// Empty fields are ignored. Date bounds are inclusive Unix timestamps; zero means unbounded.
+type EvidenceSearchFilter struct {
+	CaseID       string           `json:"caseId,omitempty"`       // Exact case ID
+	Statuses     []EvidenceStatus `json:"statuses,omitempty"`     // Any of these statuses
+	Tags         []string         `json:"tags,omitempty"`         // All of these tags
+	MetadataType string           `json:"metadataType,omitempty"` // Exact metadata.type
+	Custodian    string           `json:"custodian,omitempty"`    // Current custodian ID
+	CurrentOrg   string           `json:"currentOrg,omitempty"`   // Current custodian MSP ID
+	RegisteredBy string           `json:"registeredBy,omitempty"` // Original registrant
+	CreatedFrom  int64            `json:"createdFrom,omitempty"`
+	CreatedTo    int64            `json:"createdTo,omitempty"`
+	UpdatedFrom  int64            `json:"updatedFrom,omitempty"`
+	UpdatedTo    int64            `json:"updatedTo,omitempty"`
+	AcquiredFrom int64            `json:"acquiredFrom,omitempty"`
+	AcquiredTo   int64            `json:"acquiredTo,omitempty"`
+	SortBy       string           `json:"sortBy,omitempty"`   // createdAt (default), updatedAt or acquisitionDate
+	SortDesc     bool             `json:"sortDesc,omitempty"` // Newest first
+	PageSize     int32            `json:"pageSize,omitempty"` // Defaults to defaultSearchPageSize
+	Bookmark     string           `json:"bookmark,omitempty"` // From the previous page
+}
+
+// addRange adds an inclusive range condition on a field when either bound is set
+func addRange(selector map[string]interface{}, field string, from int64, to int64) error {
+	if from == 0 && to == 0 {
+		return nil
+	}
+	if from != 0 && to != 0 && from > to {
+		return fmt.Errorf("invalid %s range: %d is after %d", field, from, to)
+	}
+
+	condition := map[string]interface{}{}
+	if from != 0 {
+		condition["$gte"] = from
+	}
+	if to != 0 {
+		condition["$lte"] = to
+	}
+	selector[field] = condition
+	return nil
+}
+
+// selector builds the CouchDB selector for the filter
+func (f *EvidenceSearchFilter) selector() (map[string]interface{}, error) {
+	selector := map[string]interface{}{"docType": DocTypeEvidence}
+
+	exact := map[string]string{
+		"caseId":           f.CaseID,
+		"metadata.type":    f.MetadataType,
+		"currentCustodian": f.Custodian,
+		"currentOrg":       f.CurrentOrg,
+		"registeredBy":     f.RegisteredBy,
+	}
+	for field, value := range exact {
+		if value != "" {
+			selector[field] = value
+		}
+	}
+
+	if len(f.Statuses) > 0 {
+		selector["status"] = map[string]interface{}{"$in": f.Statuses}
+	}
+	if len(f.Tags) > 0 {
+		selector["tags"] = map[string]interface{}{"$all": f.Tags}
+	}
+
+	if err := addRange(selector, "createdAt", f.CreatedFrom, f.CreatedTo); err != nil {
+		return nil, err
+	}
+	if err := addRange(selector, "updatedAt", f.UpdatedFrom, f.UpdatedTo); err != nil {
+		return nil, err
+	}
+	if err := addRange(selector, "metadata.acquisitionDate", f.AcquiredFrom, f.AcquiredTo); err != nil {
+		return nil, err
+	}
+
+	return selector, nil
+}
+
+// sortFields returns the index-backed sort fields for the filter's sort key
+func (f *EvidenceSearchFilter) sortFields() ([]string, error) {
+	sortBy := f.SortBy
+	if sortBy == "" {
+		sortBy = SearchSortCreatedAt
+	}
+	fields, ok := searchSortFields[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %s: must be createdAt, updatedAt or acquisitionDate", f.SortBy)
+	}
+	return fields, nil
+}
+
+// SearchEvidence retrieves one page of evidence matching a multi-criteria filter
+// filterJSON is an EvidenceSearchFilter; unknown fields are rejected so a
+// misspelled criterion is not silently ignored.
+func (s *EvidenceContract) SearchEvidence(
+	ctx contractapi.TransactionContextInterface,
+	filterJSON string,
+) (*PaginatedEvidence, error) {
+	identity, err := RequirePermission(ctx, PermViewEvidence)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter EvidenceSearchFilter
+	if filterJSON != "" {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(filterJSON)))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&filter); err != nil {
+			return nil, fmt.Errorf("invalid search filter: %v", err)
+		}
+	}
+
+	selector, err := filter.selector()
+	if err != nil {
+		return nil, err
+	}
+	sortFields, err := filter.sortFields()
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize := filter.PageSize
+	if pageSize == 0 {
+		pageSize = defaultSearchPageSize
+	}
+
+	return pagedEvidenceQuery(ctx, identity, selector, sortFields, filter.SortDesc, pageSize, filter.Bookmark)
+}
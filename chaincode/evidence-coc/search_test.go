@@ -0,0 +1,112 @@
+// Copyright Evidentia Chain-of-Custody System
+// Tests for the SearchEvidence selector builder
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEvidenceSearchFilterSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  EvidenceSearchFilter
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "empty filter matches all evidence",
+			filter: EvidenceSearchFilter{},
+			want:   `{"docType":"evidence"}`,
+		},
+		{
+			name: "exact fields",
+			filter: EvidenceSearchFilter{
+				CaseID:       "CASE-1",
+				MetadataType: "disk_image",
+				Custodian:    "alice",
+				CurrentOrg:   "ForensicLabMSP",
+				RegisteredBy: "bob",
+			},
+			want: `{"caseId":"CASE-1","currentCustodian":"alice","currentOrg":"ForensicLabMSP","docType":"evidence","metadata.type":"disk_image","registeredBy":"bob"}`,
+		},
+		{
+			name: "statuses and tags",
+			filter: EvidenceSearchFilter{
+				Statuses: []EvidenceStatus{StatusInCustody, StatusAnalyzed},
+				Tags:     []string{"malware", "priority"},
+			},
+			want: `{"docType":"evidence","status":{"$in":["IN_CUSTODY","ANALYZED"]},"tags":{"$all":["malware","priority"]}}`,
+		},
+		{
+			name: "open and closed ranges",
+			filter: EvidenceSearchFilter{
+				CreatedFrom:  100,
+				UpdatedTo:    200,
+				AcquiredFrom: 10,
+				AcquiredTo:   20,
+			},
+			want: `{"createdAt":{"$gte":100},"docType":"evidence","metadata.acquisitionDate":{"$gte":10,"$lte":20},"updatedAt":{"$lte":200}}`,
+		},
+		{
+			name:    "inverted range",
+			filter:  EvidenceSearchFilter{CreatedFrom: 200, CreatedTo: 100},
+			wantErr: true,
+		},
+		{
+			name:   "selector syntax in a value stays a value",
+			filter: EvidenceSearchFilter{CaseID: `x","docType":{"$ne":"evidence"}`},
+			want:   `{"caseId":"x\",\"docType\":{\"$ne\":\"evidence\"}","docType":"evidence"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := tt.filter.selector()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("selector() = %v, want error", selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selector(): %v", err)
+			}
+
+			got, err := json.Marshal(selector)
+			if err != nil {
+				t.Fatalf("marshal selector: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("selector() = %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvidenceSearchFilterSortFields(t *testing.T) {
+	tests := []struct {
+		sortBy  string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{"docType", "createdAt", "id"}, false},
+		{SearchSortCreatedAt, []string{"docType", "createdAt", "id"}, false},
+		{SearchSortUpdatedAt, []string{"docType", "updatedAt", "id"}, false},
+		{SearchSortAcquisitionDate, []string{"docType", "metadata.acquisitionDate", "id"}, false},
+		{"caseId", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run("sort by "+tt.sortBy, func(t *testing.T) {
+			filter := EvidenceSearchFilter{SortBy: tt.sortBy}
+			got, err := filter.sortFields()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sortFields() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,12 +9,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
 )
 
-// GenerateID generates a unique ID based on prefix, timestamp, and optional data
-func GenerateID(prefix string, additionalData ...string) string {
-	timestamp := time.Now().UnixNano()
-	data := fmt.Sprintf("%s-%d", prefix, timestamp)
+// GetTxTimestamp returns the transaction timestamp as a Unix timestamp
+// Design Decision: time.Now() differs between endorsing peers and produces
+// mismatched write sets. The proposal timestamp is set by the client and is
+// identical on every peer that endorses the same proposal.
+func GetTxTimestamp(ctx contractapi.TransactionContextInterface) (int64, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	return ts.GetSeconds(), nil
+}
+
+// GenerateID generates a deterministic ID based on prefix, transaction ID, and optional data
+// The same proposal always yields the same ID on every endorsing peer; the
+// additional data distinguishes multiple IDs created within one transaction.
+func GenerateID(ctx contractapi.TransactionContextInterface, prefix string, additionalData ...string) string {
+	data := fmt.Sprintf("%s-%s", prefix, ctx.GetStub().GetTxID())
 	for _, d := range additionalData {
 		data += "-" + d
 	}
@@ -64,6 +64,7 @@ const Dashboard: React.FC = () => {
       ADMITTED: 'bg-emerald-500',
       REJECTED: 'bg-red-500',
       ARCHIVED: 'bg-gray-500',
+      COMPROMISED: 'bg-rose-700',
     };
     return colors[status] || 'bg-gray-500';
   };
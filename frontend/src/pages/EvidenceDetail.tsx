@@ -136,6 +136,7 @@ const EvidenceDetail: React.FC = () => {
       ADMITTED: 'bg-emerald-500',
       REJECTED: 'bg-red-500',
       ARCHIVED: 'bg-gray-500',
+      COMPROMISED: 'bg-rose-700',
     };
     return colors[status] || 'bg-gray-500';
   };
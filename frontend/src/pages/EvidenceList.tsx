@@ -54,6 +54,7 @@ const EvidenceList: React.FC = () => {
       ADMITTED: 'bg-emerald-100 text-emerald-800',
       REJECTED: 'bg-red-100 text-red-800',
       ARCHIVED: 'bg-gray-100 text-gray-800',
+      COMPROMISED: 'bg-rose-200 text-rose-900',
     };
     return colors[status] || 'bg-gray-100 text-gray-800';
   };
@@ -82,6 +83,7 @@ const EvidenceList: React.FC = () => {
     'ADMITTED',
     'REJECTED',
     'ARCHIVED',
+    'COMPROMISED',
   ];
 
   return (